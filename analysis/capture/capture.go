@@ -0,0 +1,328 @@
+// Package capture defines an analyzer that checks Falta.Capture and Falta.CaptureCtx are used the only way they work:
+//
+//	func Load(id int) (_ User, err error) {
+//		defer ErrCannotLoad.New(id).Capture(&err)
+//		...
+//	}
+//
+// The same goes for the falta.Capture and falta.CaptureCtx functions, as in
+// defer falta.Capture(&err, ErrCannotLoad, id).
+//
+// Capture must be deferred, so it runs after the function's result is set. Its argument must be the address of the
+// function's named error result, since that is the variable the return statements set; the address of any other
+// variable wraps an error nobody returns. And the arguments of the factory call it is deferred on are evaluated at
+// the defer statement, so a variable among them that is assigned later appears in the error with its old value.
+package capture
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/inspect"
+	"golang.org/x/tools/go/ast/astutil"
+	"golang.org/x/tools/go/ast/inspector"
+)
+
+const faltaPath = "github.com/a20r/falta"
+
+// Analyzer reports misuses of Falta.Capture and Falta.CaptureCtx.
+var Analyzer = &analysis.Analyzer{
+	Name:     "capture",
+	Doc:      "check that Falta.Capture is deferred on the address of the function's named error result",
+	Requires: []*analysis.Analyzer{inspect.Analyzer},
+	Run:      run,
+}
+
+func run(pass *analysis.Pass) (any, error) {
+	insp := pass.ResultOf[inspect.Analyzer].(*inspector.Inspector)
+
+	insp.WithStack([]ast.Node{(*ast.CallExpr)(nil)}, func(n ast.Node, push bool, stack []ast.Node) bool {
+		call := n.(*ast.CallExpr)
+		name, errArg := captureCall(pass, call)
+
+		if !push || errArg == nil {
+			return true
+		}
+
+		deferred, fn := enclosing(call, stack)
+
+		if deferred == nil {
+			pass.Reportf(call.Pos(), "%s is not deferred, so it wraps the error before the function returns it, if at "+
+				"all; call it as defer ...%s(&err) at the top of the function", name, name)
+			return true
+		}
+
+		result := namedErrorResult(pass, fn)
+
+		if result == nil {
+			pass.Reportf(call.Pos(), "%s is used in a function without a named error result, so it cannot change the "+
+				"error the function returns; name the result, as in (_ T, err error)", name)
+			return true
+		}
+
+		if !addressOf(pass, errArg, result) {
+			pass.Reportf(errArg.Pos(), "%s must be passed &%s, the function's named error result, so it wraps the "+
+				"error the function returns", name, result.Name())
+		}
+
+		if deferred.Call == call {
+			checkStaleArgs(pass, call, errArg, deferred, fn)
+		}
+
+		return true
+	})
+
+	return nil, nil
+}
+
+// captureCall returns the name of the falta method or function call calls, if it is Capture or CaptureCtx, and the
+// argument it is passed the address of the error in. It returns a nil argument for any other call.
+func captureCall(pass *analysis.Pass, call *ast.CallExpr) (string, ast.Expr) {
+	fn := calledFunc(pass, call)
+
+	if fn == nil || fn.Pkg() == nil || fn.Pkg().Path() != faltaPath {
+		return "", nil
+	}
+
+	if fn.Name() != "Capture" && fn.Name() != "CaptureCtx" {
+		return "", nil
+	}
+
+	// The methods take the error last, the functions before the factory, after the context if they take one.
+	index := len(call.Args) - 1
+
+	if fn.Type().(*types.Signature).Recv() == nil {
+		index = 0
+
+		if fn.Name() == "CaptureCtx" {
+			index = 1
+		}
+	}
+
+	if index < 0 || index >= len(call.Args) {
+		return "", nil
+	}
+
+	return fn.Name(), call.Args[index]
+}
+
+// calledFunc returns the function or method call calls, or nil if it calls something else, such as a function value.
+func calledFunc(pass *analysis.Pass, call *ast.CallExpr) *types.Func {
+	var obj types.Object
+
+	switch fun := astutil.Unparen(call.Fun).(type) {
+	case *ast.Ident:
+		obj = pass.TypesInfo.Uses[fun]
+	case *ast.SelectorExpr:
+		obj = pass.TypesInfo.Uses[fun.Sel]
+	}
+
+	fn, _ := obj.(*types.Func)
+	return fn
+}
+
+// enclosing returns the defer statement that defers call, either directly or in a deferred function literal, and the
+// function whose results it captures. It returns a nil statement if call is not deferred.
+func enclosing(call *ast.CallExpr, stack []ast.Node) (*ast.DeferStmt, ast.Node) {
+	var deferred *ast.DeferStmt
+	target := ast.Node(call)
+
+	for i := len(stack) - 2; i >= 0; i-- {
+		switch n := stack[i].(type) {
+		case *ast.DeferStmt:
+			if n.Call == target {
+				deferred = n
+			}
+		case *ast.FuncLit:
+			if deferred != nil {
+				return deferred, n
+			}
+
+			// A function literal deferred as a whole runs at the end of the function around it, so the call in it
+			// counts as deferred in that function.
+			if i >= 2 {
+				if outer, ok := stack[i-1].(*ast.CallExpr); ok && outer.Fun == n && len(outer.Args) == 0 {
+					if d, ok := stack[i-2].(*ast.DeferStmt); ok && d.Call == outer {
+						deferred = d
+						target = nil
+						i -= 2
+
+						continue
+					}
+				}
+			}
+
+			return nil, n
+		case *ast.FuncDecl:
+			if deferred == nil {
+				return nil, n
+			}
+
+			return deferred, n
+		}
+	}
+
+	return nil, nil
+}
+
+// namedErrorResult returns the variable of fn's named error result, or nil if it has none.
+func namedErrorResult(pass *analysis.Pass, fn ast.Node) *types.Var {
+	var results *ast.FieldList
+
+	switch fn := fn.(type) {
+	case *ast.FuncDecl:
+		results = fn.Type.Results
+	case *ast.FuncLit:
+		results = fn.Type.Results
+	}
+
+	if results == nil {
+		return nil
+	}
+
+	for _, field := range results.List {
+		for _, name := range field.Names {
+			v, ok := pass.TypesInfo.Defs[name].(*types.Var)
+
+			if ok && name.Name != "_" && types.Identical(v.Type(), errorType) {
+				return v
+			}
+		}
+	}
+
+	return nil
+}
+
+var errorType = types.Universe.Lookup("error").Type()
+
+// addressOf reports whether expr is &v.
+func addressOf(pass *analysis.Pass, expr ast.Expr, v *types.Var) bool {
+	unary, ok := astutil.Unparen(expr).(*ast.UnaryExpr)
+
+	if !ok || unary.Op != token.AND {
+		return false
+	}
+
+	ident, ok := astutil.Unparen(unary.X).(*ast.Ident)
+	return ok && pass.TypesInfo.Uses[ident] == v
+}
+
+// checkStaleArgs reports the local variables among the arguments of the calls Capture is deferred on, or of the
+// Capture function itself, that are assigned after the defer statement. Their values were already taken when the
+// defer statement ran.
+func checkStaleArgs(pass *analysis.Pass, call *ast.CallExpr, errArg ast.Expr, deferred *ast.DeferStmt, fn ast.Node) {
+	var body *ast.BlockStmt
+
+	switch fn := fn.(type) {
+	case *ast.FuncDecl:
+		body = fn.Body
+	case *ast.FuncLit:
+		body = fn.Body
+	}
+
+	if body == nil {
+		return
+	}
+
+	reported := make(map[types.Object]bool)
+
+	for _, arg := range deferredArgs(pass, call, errArg) {
+		ast.Inspect(arg, func(n ast.Node) bool {
+			ident, isIdent := n.(*ast.Ident)
+
+			if !isIdent {
+				return true
+			}
+
+			v, isVar := pass.TypesInfo.Uses[ident].(*types.Var)
+
+			if !isVar || reported[v] || v.Parent() == nil || v.Parent() == v.Pkg().Scope() {
+				return true
+			}
+
+			if pos := assignedAfter(pass, body, v, deferred.End()); pos.IsValid() {
+				reported[v] = true
+				pass.Reportf(ident.Pos(), "%s is evaluated when Capture is deferred, but is assigned again on line %d, "+
+					"so the error will have its old value; wrap the Capture in a deferred function literal",
+					ident.Name, pass.Fset.Position(pos).Line)
+			}
+
+			return true
+		})
+	}
+}
+
+// deferredArgs returns the arguments that build the error a Capture call wraps: those after errArg for the function,
+// and those of the chain of calls the method is called on, as in ErrX.New(id).Annotatef("attempt %d", n).
+func deferredArgs(pass *analysis.Pass, call *ast.CallExpr, errArg ast.Expr) []ast.Expr {
+	if calledFunc(pass, call).Type().(*types.Signature).Recv() == nil {
+		for i, arg := range call.Args {
+			if arg == errArg {
+				return call.Args[i+1:]
+			}
+		}
+
+		return nil
+	}
+
+	var args []ast.Expr
+	receiver := astutil.Unparen(call.Fun).(*ast.SelectorExpr).X
+
+	for {
+		inner, ok := astutil.Unparen(receiver).(*ast.CallExpr)
+
+		if !ok {
+			return args
+		}
+
+		args = append(args, inner.Args...)
+		sel, ok := astutil.Unparen(inner.Fun).(*ast.SelectorExpr)
+
+		if !ok {
+			return args
+		}
+
+		receiver = sel.X
+	}
+}
+
+// assignedAfter returns the position of the first assignment to v in body after pos, or token.NoPos.
+func assignedAfter(pass *analysis.Pass, body *ast.BlockStmt, v *types.Var, pos token.Pos) token.Pos {
+	found := token.NoPos
+
+	assigns := func(expr ast.Expr) bool {
+		ident, ok := astutil.Unparen(expr).(*ast.Ident)
+		return ok && pass.TypesInfo.Uses[ident] == v
+	}
+
+	record := func(at token.Pos) {
+		if at > pos && (!found.IsValid() || at < found) {
+			found = at
+		}
+	}
+
+	ast.Inspect(body, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.AssignStmt:
+			for _, lhs := range n.Lhs {
+				if assigns(lhs) {
+					record(n.Pos())
+				}
+			}
+		case *ast.IncDecStmt:
+			if assigns(n.X) {
+				record(n.Pos())
+			}
+		case *ast.RangeStmt:
+			if n.Key != nil && assigns(n.Key) || n.Value != nil && assigns(n.Value) {
+				record(n.Pos())
+			}
+		}
+
+		return true
+	})
+
+	return found
+}
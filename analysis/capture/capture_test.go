@@ -0,0 +1,13 @@
+package capture_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/a20r/falta/analysis/capture"
+	"golang.org/x/tools/go/analysis/analysistest"
+)
+
+func TestAnalyzer(t *testing.T) {
+	analysistest.Run(t, filepath.Join(analysistest.TestData(), "..", "..", "testdata"), capture.Analyzer, "capture")
+}
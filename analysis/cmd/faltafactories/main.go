@@ -0,0 +1,84 @@
+// Command faltafactories reports the falta factories of a module that nothing builds errors with, and those declared
+// with the same string as another, which errors.Is cannot tell apart.
+//
+//	faltafactories ./...
+//
+// It needs to see every package that may use a factory, so pass it the whole module. Uses in tests count. It exits
+// with status 1 if it reports anything.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/a20r/falta/analysis/factories"
+	"github.com/a20r/falta/analysis/internal/paths"
+	"golang.org/x/tools/go/packages"
+)
+
+func main() {
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "usage: faltafactories [packages]\n")
+		flag.PrintDefaults()
+	}
+
+	flag.Parse()
+
+	patterns := flag.Args()
+
+	if len(patterns) == 0 {
+		patterns = []string{"./..."}
+	}
+
+	n, err := run(os.Stdout, patterns)
+
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "faltafactories:", err)
+		os.Exit(2)
+	}
+
+	if n > 0 {
+		os.Exit(1)
+	}
+}
+
+func run(w io.Writer, patterns []string) (int, error) {
+	cfg := &packages.Config{
+		Mode: packages.NeedName | packages.NeedFiles | packages.NeedImports | packages.NeedDeps | packages.NeedSyntax |
+			packages.NeedTypes | packages.NeedTypesInfo,
+		Tests: true,
+	}
+
+	pkgs, err := packages.Load(cfg, patterns...)
+
+	if err != nil {
+		return 0, err
+	}
+
+	if packages.PrintErrors(pkgs) > 0 {
+		return 0, fmt.Errorf("cannot load packages")
+	}
+
+	var checked []factories.Package
+
+	for _, pkg := range pkgs {
+		// The test binary's main package is generated, and declares nothing.
+		if strings.HasSuffix(pkg.ID, ".test") {
+			continue
+		}
+
+		checked = append(checked, factories.Package{Fset: pkg.Fset, Files: pkg.Syntax, Info: pkg.TypesInfo})
+	}
+
+	problems := factories.Check(checked)
+
+	for _, problem := range problems {
+		problem.Pos.Filename = paths.Relative(problem.Pos.Filename)
+		fmt.Fprintf(w, "%s: %s\n", problem.Pos, problem.Message)
+	}
+
+	return len(problems), nil
+}
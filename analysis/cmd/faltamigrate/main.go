@@ -0,0 +1,136 @@
+// Command faltamigrate finds the errors a module builds ad hoc with fmt.Errorf and errors.New, groups the ones worded
+// alike, and can rewrite them to falta factories.
+//
+//	faltamigrate ./...        # report the clusters of similar errors
+//	faltamigrate -fix ./...   # and rewrite every call it can
+//
+// Each cluster lists its call sites and wordings, so the same failure worded several ways can be given one wording.
+// With -fix, each package gets a factory for each declaration its calls use, and its files are written back
+// formatted. Calls that cannot keep their message, such as those with %w in the middle of the format, are reported
+// and left as they are.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"go/token"
+	"io"
+	"os"
+
+	"github.com/a20r/falta/analysis/errorf"
+	"github.com/a20r/falta/analysis/internal/paths"
+	"golang.org/x/tools/go/packages"
+)
+
+func main() {
+	fix := flag.Bool("fix", false, "rewrite the calls to falta factories")
+	all := flag.Bool("all", false, "also report the errors that are worded like no other")
+
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "usage: faltamigrate [-fix] [-all] [packages]\n")
+		flag.PrintDefaults()
+	}
+
+	flag.Parse()
+
+	patterns := flag.Args()
+
+	if len(patterns) == 0 {
+		patterns = []string{"./..."}
+	}
+
+	if err := run(os.Stdout, patterns, *fix, *all); err != nil {
+		fmt.Fprintln(os.Stderr, "faltamigrate:", err)
+		os.Exit(1)
+	}
+}
+
+func run(w io.Writer, patterns []string, fix, all bool) error {
+	cfg := &packages.Config{
+		Mode: packages.NeedName | packages.NeedFiles | packages.NeedImports | packages.NeedDeps | packages.NeedSyntax |
+			packages.NeedTypes | packages.NeedTypesInfo,
+	}
+	pkgs, err := packages.Load(cfg, patterns...)
+
+	if err != nil {
+		return err
+	}
+
+	if packages.PrintErrors(pkgs) > 0 {
+		return fmt.Errorf("cannot load packages")
+	}
+
+	if len(pkgs) == 0 {
+		fmt.Fprintln(w, "no packages to migrate")
+		return nil
+	}
+
+	var sites []errorf.Site
+	byPackage := make(map[*packages.Package][]errorf.Site)
+
+	for _, pkg := range pkgs {
+		found := errorf.Find(pkg.Fset, pkg.Syntax, pkg.TypesInfo)
+		byPackage[pkg] = found
+		sites = append(sites, found...)
+	}
+
+	report(w, pkgs[0].Fset, sites, all)
+
+	if !fix {
+		return nil
+	}
+
+	rewrote := false
+
+	for _, pkg := range pkgs {
+		files, err := errorf.Rewrite(pkg.Fset, pkg.Types, byPackage[pkg])
+
+		if err != nil {
+			return err
+		}
+
+		for filename, src := range files {
+			if err := os.WriteFile(filename, src, 0o644); err != nil { //nolint:gosec // keeps the usual mode of source files
+				return err
+			}
+
+			fmt.Fprintln(w, "rewrote", paths.Relative(filename))
+			rewrote = true
+		}
+	}
+
+	if rewrote {
+		fmt.Fprintln(w, "if the module does not require falta yet, run: go get github.com/a20r/falta")
+	}
+
+	return nil
+}
+
+// report prints the clusters of sites, and the sites that cannot be rewritten.
+func report(w io.Writer, fset *token.FileSet, sites []errorf.Site, all bool) {
+	n := 0
+
+	for _, cluster := range errorf.Cluster(sites) {
+		if len(cluster) < 2 && !all {
+			continue
+		}
+
+		n++
+		wordings := errorf.Wordings(cluster)
+		fmt.Fprintf(w, "cluster %d: %d calls, %d wordings\n", n, len(cluster), len(wordings))
+
+		for _, wording := range wordings {
+			fmt.Fprintf(w, "\t%q\n", wording)
+		}
+
+		for _, site := range cluster {
+			fmt.Fprintf(w, "\t\t%s: %s\n", paths.Relative(fset.Position(site.Call.Pos()).String()), site.Func)
+		}
+	}
+
+	for _, site := range sites {
+		if !site.Rewritable() {
+			fmt.Fprintf(w, "cannot rewrite %s: %s\n", paths.Relative(fset.Position(site.Call.Pos()).String()), site.Reason)
+		}
+	}
+}
@@ -0,0 +1,25 @@
+// Command faltavet runs the falta analyzers. Run it directly, as in
+//
+//	faltavet ./...
+//
+// or through go vet, as in
+//
+//	go vet -vettool=$(which faltavet) ./...
+package main
+
+import (
+	"github.com/a20r/falta/analysis/capture"
+	"github.com/a20r/falta/analysis/errorf"
+	"github.com/a20r/falta/analysis/errset"
+	"github.com/a20r/falta/analysis/verbs"
+	"golang.org/x/tools/go/analysis/multichecker"
+)
+
+func main() {
+	multichecker.Main(
+		capture.Analyzer,
+		errorf.Analyzer,
+		errset.Analyzer,
+		verbs.Analyzer,
+	)
+}
@@ -0,0 +1,143 @@
+package errorf
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// Similarity is how alike two formats must be, as the share of words they have in common, for Cluster to put them
+// in the same cluster. Verbs and the words of two letters or fewer do not count.
+const Similarity = 0.5
+
+// Cluster groups sites whose formats are worded alike: the same once verbs, case and spacing are ignored, or sharing
+// at least Similarity of their words. Each cluster is in the order of sites, and the clusters are in the order of
+// their first site. Sites alike to nothing else are clusters of one.
+func Cluster(sites []Site) [][]Site {
+	parent := make([]int, len(sites))
+	words := make([]map[string]bool, len(sites))
+
+	for i, site := range sites {
+		parent[i] = i
+		words[i] = wordsOf(site.Format)
+	}
+
+	var find func(i int) int
+
+	find = func(i int) int {
+		if parent[i] != i {
+			parent[i] = find(parent[i])
+		}
+
+		return parent[i]
+	}
+
+	for i := range sites {
+		for j := 0; j < i; j++ {
+			if Normalize(sites[i].Format) == Normalize(sites[j].Format) || jaccard(words[i], words[j]) >= Similarity {
+				parent[find(i)] = find(j)
+			}
+		}
+	}
+
+	index := make(map[int]int)
+	var clusters [][]Site
+
+	for i, site := range sites {
+		root := find(i)
+		n, ok := index[root]
+
+		if !ok {
+			n = len(clusters)
+			index[root] = n
+			clusters = append(clusters, nil)
+		}
+
+		clusters[n] = append(clusters[n], site)
+	}
+
+	return clusters
+}
+
+// Wordings returns the distinct formats of a cluster, sorted.
+func Wordings(cluster []Site) []string {
+	seen := make(map[string]bool)
+	var wordings []string
+
+	for _, site := range cluster {
+		if !seen[site.Format] {
+			seen[site.Format] = true
+			wordings = append(wordings, site.Format)
+		}
+	}
+
+	sort.Strings(wordings)
+	return wordings
+}
+
+// Normalize returns a format with every verb replaced by %v, a trailing ": %w" removed, and letters lowercased and
+// spaces collapsed, so that formats that differ only in those compare equal.
+func Normalize(format string) string {
+	format = strings.TrimSuffix(format, ": %w")
+	verbs, ok := parseVerbs(format)
+
+	if ok && len(verbs) > 0 {
+		var builder strings.Builder
+		runes := []rune(format)
+
+		for i := 0; i < len(runes); i++ {
+			if runes[i] != '%' || i+1 == len(runes) {
+				builder.WriteRune(runes[i])
+				continue
+			}
+
+			if runes[i+1] == '%' {
+				builder.WriteString("%%")
+				i++
+
+				continue
+			}
+
+			for i < len(runes) && !unicode.IsLetter(runes[i]) {
+				i++
+			}
+
+			builder.WriteString("%v")
+		}
+
+		format = builder.String()
+	}
+
+	return strings.Join(strings.Fields(strings.ToLower(format)), " ")
+}
+
+func wordsOf(format string) map[string]bool {
+	words := make(map[string]bool)
+	fields := strings.FieldsFunc(strings.ToLower(Normalize(format)), func(r rune) bool {
+		return !unicode.IsLetter(r) && r != '%'
+	})
+
+	for _, word := range fields {
+		if len(word) > 2 && !strings.HasPrefix(word, "%") {
+			words[word] = true
+		}
+	}
+
+	return words
+}
+
+func jaccard(a, b map[string]bool) float64 {
+	if len(a) == 0 || len(b) == 0 {
+		return 0
+	}
+
+	shared := 0
+
+	for word := range a {
+		if b[word] {
+			shared++
+		}
+	}
+
+	return float64(shared) / float64(len(a)+len(b)-shared)
+}
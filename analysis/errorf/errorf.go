@@ -0,0 +1,59 @@
+// Package errorf finds the errors built ad hoc with fmt.Errorf and errors.New, and rewrites them to falta factories.
+//
+// The same failure tends to get worded a different way at every call site that reports it. Find returns the call
+// sites of a package, Cluster groups the ones that are worded alike, and Rewrite replaces them with package-level
+// factories:
+//
+//	return fmt.Errorf("cannot load user %d: %w", id, err)
+//
+// becomes
+//
+//	var errCannotLoadUser = falta.Newf("cannot load user %d")
+//	...
+//	return errCannotLoadUser.New(id).Wrap(err)
+//
+// Analyzer reports the duplicates within a package, and the faltamigrate command reports the clusters across a module
+// and, with -fix, rewrites it.
+package errorf
+
+import (
+	"fmt"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+)
+
+// Analyzer reports the ad-hoc errors of a package that are worded the same as another of its ad-hoc errors, once verbs,
+// case and spacing are ignored. It leaves out the looser clusters of faltamigrate, which are too often different
+// failures to report from vet.
+var Analyzer = &analysis.Analyzer{
+	Name: "errorf",
+	Doc:  "report errors built with fmt.Errorf and errors.New that are worded the same as others in the package",
+	Run:  run,
+}
+
+func run(pass *analysis.Pass) (any, error) {
+	sites := Find(pass.Fset, pass.Files, pass.TypesInfo)
+	byWording := make(map[string][]Site)
+
+	for _, site := range sites {
+		byWording[Normalize(site.Format)] = append(byWording[Normalize(site.Format)], site)
+	}
+
+	for _, site := range sites {
+		var others []string
+
+		for _, other := range byWording[Normalize(site.Format)] {
+			if other.Call != site.Call {
+				others = append(others, fmt.Sprintf("%q on line %d", other.Format, pass.Fset.Position(other.Call.Pos()).Line))
+			}
+		}
+
+		if len(others) > 0 {
+			pass.Reportf(site.Call.Pos(), "%s %q is worded the same as other ad-hoc errors in the package (%s); declare "+
+				"one falta factory for them, which faltamigrate -fix does", site.Func, site.Format, strings.Join(others, ", "))
+		}
+	}
+
+	return nil, nil
+}
@@ -0,0 +1,13 @@
+package errorf_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/a20r/falta/analysis/errorf"
+	"golang.org/x/tools/go/analysis/analysistest"
+)
+
+func TestAnalyzer(t *testing.T) {
+	analysistest.Run(t, filepath.Join(analysistest.TestData(), "..", "..", "testdata"), errorf.Analyzer, "errorf")
+}
@@ -0,0 +1,310 @@
+package errorf
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"golang.org/x/tools/go/ast/astutil"
+	"golang.org/x/tools/imports"
+)
+
+const faltaPath = "github.com/a20r/falta"
+
+// Rewrite replaces the rewritable sites of one package by falta factories, and returns the new contents of the files
+// it changed, gofmt'd, by file name. Sites with the same declaration share one factory, declared as a package-level
+// variable in the file of the first of them: falta.NewError if the declaration formats nothing, and falta.Newf
+// otherwise. Each call becomes errX, errX.New(args), or either followed by .Wrap(cause) if the call wrapped an error
+// with %w. A call that declares a variable, as in err := errors.New("x"), is converted with error(...), so that the
+// variable keeps the type error and can still be assigned other errors.
+//
+// The files are read from disk, so sites must come from files parsed from there.
+func Rewrite(fset *token.FileSet, pkg *types.Package, sites []Site) (map[string][]byte, error) {
+	factories := make(map[string]*factory)
+	edits := make(map[string][]edit)
+	taken := make(map[string]bool)
+	defining := make(map[*ast.File]map[*ast.CallExpr]bool)
+	var order []*factory
+
+	for _, site := range sites {
+		if !site.Rewritable() {
+			continue
+		}
+
+		kind := "Newf"
+
+		if len(site.Args) == 0 {
+			kind = "NewError"
+		}
+
+		key := kind + "\x00" + site.Declaration
+		f, ok := factories[key]
+
+		if !ok {
+			f = &factory{name: factoryName(site.Declaration, pkg, taken), kind: kind, declaration: site.Declaration}
+			f.file = fset.Position(site.File.Package).Filename
+			f.afterImports = afterImports(fset, site.File)
+			factories[key] = f
+			order = append(order, f)
+		}
+
+		if defining[site.File] == nil {
+			defining[site.File] = definingCalls(site.File)
+		}
+
+		filename := fset.Position(site.Call.Pos()).Filename
+		edits[filename] = append(edits[filename], edit{
+			start:  fset.Position(site.Call.Pos()).Offset,
+			end:    fset.Position(site.Call.End()).Offset,
+			call:   &site,
+			f:      f,
+			define: defining[site.File][site.Call],
+		})
+	}
+
+	declared := make(map[string][]*factory)
+
+	for _, f := range order {
+		declared[f.file] = append(declared[f.file], f)
+	}
+
+	for filename, fs := range declared {
+		at := fs[0].afterImports
+		edits[filename] = append(edits[filename], edit{start: at, end: at, declare: fs})
+	}
+
+	files := make(map[string][]byte, len(edits))
+
+	for filename, fileEdits := range edits {
+		src, err := os.ReadFile(filename)
+
+		if err != nil {
+			return nil, err
+		}
+
+		out, err := apply(fset, filename, src, fileEdits)
+
+		if err != nil {
+			return nil, err
+		}
+
+		files[filename] = out
+	}
+
+	return files, nil
+}
+
+type factory struct {
+	name, kind, declaration string
+	file                    string
+	afterImports            int
+}
+
+// edit replaces the bytes from start to end by a call to f, or inserts the declarations of factories. If define is
+// set, the call is the value of a variable declared without a type, which must stay an error.
+type edit struct {
+	start, end int
+	call       *Site
+	f          *factory
+	define     bool
+	declare    []*factory
+}
+
+// text returns the replacement for the edit. The sources of the call's arguments have the edits among others that
+// fall inside them applied, so that a call nested in another one is rewritten too.
+func (e edit) text(src []byte, fset *token.FileSet, others []edit) string {
+	if len(e.declare) == 1 {
+		f := e.declare[0]
+		return fmt.Sprintf("\n\nvar %s = falta.%s(%s)", f.name, f.kind, strconv.Quote(f.declaration))
+	}
+
+	if len(e.declare) > 1 {
+		var builder strings.Builder
+		builder.WriteString("\n\nvar (\n")
+
+		for _, f := range e.declare {
+			fmt.Fprintf(&builder, "\t%s = falta.%s(%s)\n", f.name, f.kind, strconv.Quote(f.declaration))
+		}
+
+		builder.WriteString(")")
+		return builder.String()
+	}
+
+	var builder strings.Builder
+	builder.WriteString(e.f.name)
+
+	if e.f.kind == "Newf" {
+		args := make([]string, len(e.call.Args))
+
+		for i, arg := range e.call.Args {
+			args[i] = source(src, fset, arg, others)
+		}
+
+		builder.WriteString(".New(" + strings.Join(args, ", ") + ")")
+	}
+
+	if e.call.Cause != nil {
+		builder.WriteString(".Wrap(" + source(src, fset, e.call.Cause, others) + ")")
+	}
+
+	if e.define {
+		return "error(" + builder.String() + ")"
+	}
+
+	return builder.String()
+}
+
+// definingCalls returns the calls in file that are the values of variables declared without a type, by := or var,
+// whose type would change from error to falta.Falta if the calls were replaced as they are.
+func definingCalls(file *ast.File) map[*ast.CallExpr]bool {
+	calls := make(map[*ast.CallExpr]bool)
+
+	add := func(values []ast.Expr) {
+		for _, value := range values {
+			if call, ok := astutil.Unparen(value).(*ast.CallExpr); ok {
+				calls[call] = true
+			}
+		}
+	}
+
+	ast.Inspect(file, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.AssignStmt:
+			if n.Tok == token.DEFINE {
+				add(n.Rhs)
+			}
+		case *ast.ValueSpec:
+			if n.Type == nil {
+				add(n.Values)
+			}
+		}
+
+		return true
+	})
+
+	return calls
+}
+
+// source returns the source of node with the edits that fall inside it applied.
+func source(src []byte, fset *token.FileSet, node ast.Node, edits []edit) string {
+	return splice(src, fset, fset.Position(node.Pos()).Offset, fset.Position(node.End()).Offset, edits)
+}
+
+// splice returns src from start to end with the edits in that range applied. An edit inside another one is left to
+// the outer edit's text. The edits must be sorted by start, and outer edits before the ones inside them.
+func splice(src []byte, fset *token.FileSet, start, end int, edits []edit) string {
+	var builder strings.Builder
+	pos := start
+
+	for _, e := range edits {
+		if e.start < pos || e.end > end {
+			continue
+		}
+
+		builder.Write(src[pos:e.start])
+		builder.WriteString(e.text(src, fset, edits))
+		pos = e.end
+	}
+
+	builder.Write(src[pos:end])
+	return builder.String()
+}
+
+// apply applies the edits to src, then adds the falta import, removes the imports of fmt and errors if they are no
+// longer used, and formats the result as goimports would.
+func apply(siteFset *token.FileSet, filename string, src []byte, edits []edit) ([]byte, error) {
+	sort.SliceStable(edits, func(i, j int) bool {
+		if edits[i].start != edits[j].start {
+			return edits[i].start < edits[j].start
+		}
+
+		return edits[i].end > edits[j].end
+	})
+
+	fset := token.NewFileSet()
+	out := []byte(splice(src, siteFset, 0, len(src), edits))
+
+	file, err := parser.ParseFile(fset, filename, out, parser.ParseComments)
+
+	if err != nil {
+		return nil, fmt.Errorf("rewriting %s: %w", filename, err)
+	}
+
+	astutil.AddImport(fset, file, faltaPath)
+
+	for _, path := range []string{"errors", "fmt"} {
+		if !astutil.UsesImport(file, path) {
+			astutil.DeleteImport(fset, file, path)
+		}
+	}
+
+	var buf bytes.Buffer
+
+	if err := format.Node(&buf, fset, file); err != nil {
+		return nil, fmt.Errorf("formatting %s: %w", filename, err)
+	}
+
+	// Process puts the falta import in a group of its own, apart from the standard library.
+	return imports.Process(filename, buf.Bytes(), &imports.Options{Comments: true, TabIndent: true, TabWidth: 8, FormatOnly: true})
+}
+
+// afterImports returns the offset in the file just after its imports, or its package clause if it has none.
+func afterImports(fset *token.FileSet, file *ast.File) int {
+	end := file.Name.End()
+
+	for _, decl := range file.Decls {
+		if gen, ok := decl.(*ast.GenDecl); ok && gen.Tok == token.IMPORT {
+			end = gen.End()
+		}
+	}
+
+	return fset.Position(end).Offset
+}
+
+// factoryName names a factory after the first words of its declaration, as in errCannotLoadUser for
+// "cannot load user %d", avoiding the names already taken in the package and by other factories.
+func factoryName(declaration string, pkg *types.Package, taken map[string]bool) string {
+	var words []string
+	fields := strings.FieldsFunc(declaration, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '%'
+	})
+
+	for _, word := range fields {
+		if strings.HasPrefix(word, "%") || len(words) == 4 {
+			continue
+		}
+
+		runes := []rune(strings.ToLower(word))
+
+		if !unicode.IsLetter(runes[0]) {
+			continue
+		}
+
+		runes[0] = unicode.ToUpper(runes[0])
+		words = append(words, string(runes))
+	}
+
+	base := "err" + strings.Join(words, "")
+
+	if len(words) == 0 {
+		base = "errAdHoc"
+	}
+
+	name := base
+
+	for i := 2; taken[name] || pkg.Scope().Lookup(name) != nil; i++ {
+		name = base + strconv.Itoa(i)
+	}
+
+	taken[name] = true
+	return name
+}
@@ -0,0 +1,82 @@
+package errorf_test
+
+import (
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/a20r/falta/analysis/errorf"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRewrite(t *testing.T) {
+	as := assert.New(t)
+
+	dir := t.TempDir()
+	fset := token.NewFileSet()
+	var files []*ast.File
+
+	for _, name := range []string{"store.go", "cache.go"} {
+		src, err := os.ReadFile(filepath.Join("..", "testdata", "src", "migrate", name))
+		as.NoError(err)
+		as.NoError(os.WriteFile(filepath.Join(dir, name), src, 0o600))
+
+		file, err := parser.ParseFile(fset, filepath.Join(dir, name), src, parser.ParseComments)
+		as.NoError(err)
+		files = append(files, file)
+	}
+
+	info := &types.Info{Types: make(map[ast.Expr]types.TypeAndValue), Uses: make(map[*ast.Ident]types.Object)}
+	pkg, err := (&types.Config{Importer: importer.Default()}).Check("migrate", fset, files, info)
+	as.NoError(err)
+
+	sites := errorf.Find(fset, files, info)
+	as.Len(sites, 10)
+	as.False(sites[4].Rewritable())
+	as.Equal(`%w is not at the end of the format, after ": ", where Wrap puts the cause`, sites[4].Reason)
+
+	out, err := errorf.Rewrite(fset, pkg, sites)
+	as.NoError(err)
+	as.Len(out, 2)
+
+	for _, name := range []string{"store.go", "cache.go"} {
+		golden, err := os.ReadFile(filepath.Join("..", "testdata", "src", "migrate", name+".golden"))
+		as.NoError(err)
+		as.Equal(string(golden), string(out[filepath.Join(dir, name)]), name)
+	}
+}
+
+func TestCluster(t *testing.T) {
+	as := assert.New(t)
+
+	site := func(format string) errorf.Site {
+		return errorf.Site{Format: format}
+	}
+
+	clusters := errorf.Cluster([]errorf.Site{
+		site("cannot load user %d: %w"),
+		site("store closed"),
+		site("Cannot  load user %v"),
+		site("user %d could not be loaded from the store"),
+		site("store is closed"),
+		site("failed to load user %d"),
+	})
+
+	as.Len(clusters, 3)
+	as.Equal([]string{"Cannot  load user %v", "cannot load user %d: %w", "failed to load user %d"}, errorf.Wordings(clusters[0]))
+	as.Equal([]string{"store closed", "store is closed"}, errorf.Wordings(clusters[1]))
+	as.Len(clusters[2], 1)
+}
+
+func TestNormalize(t *testing.T) {
+	as := assert.New(t)
+
+	as.Equal("cannot load user %v", errorf.Normalize("Cannot  load user %-5d: %w"))
+	as.Equal("disk is 100%% full", errorf.Normalize("disk is 100%% full"))
+	as.Equal("no verbs", errorf.Normalize(" No verbs "))
+}
@@ -0,0 +1,227 @@
+package errorf
+
+import (
+	"go/ast"
+	"go/constant"
+	"go/token"
+	"go/types"
+	"strings"
+	"unicode"
+)
+
+// Site is a call to fmt.Errorf or errors.New with a constant format, outside of a package-level declaration.
+type Site struct {
+	// Call is the call expression, and File the file it is in.
+	Call *ast.CallExpr
+	File *ast.File
+
+	// Func is "fmt.Errorf" or "errors.New".
+	Func string
+
+	// Format is the constant format string, as the call has it.
+	Format string
+
+	// Declaration is what the factory replacing the call would be declared with: Format without a trailing ": %w",
+	// and with %% unescaped if it formats no arguments, since falta.NewError does not run fmt. It is empty if the call
+	// cannot be rewritten, and Reason says why.
+	Declaration string
+	Reason      string
+
+	// Args are the arguments of the call that the declaration formats, and Cause is the one %w wraps, if any.
+	Args  []ast.Expr
+	Cause ast.Expr
+}
+
+// Rewritable reports whether the call can be replaced by a falta factory.
+func (s Site) Rewritable() bool {
+	return s.Declaration != ""
+}
+
+// Find returns the sites in files, in the order they appear. Test files are skipped, since their ad-hoc errors are
+// usually made up on the spot to be returned by a fake.
+func Find(fset *token.FileSet, files []*ast.File, info *types.Info) []Site {
+	var sites []Site
+
+	for _, file := range files {
+		if strings.HasSuffix(fset.Position(file.Package).Filename, "_test.go") {
+			continue
+		}
+
+		for _, decl := range file.Decls {
+			// A package-level var is already declared once, and so is not ad hoc.
+			if gen, ok := decl.(*ast.GenDecl); ok && gen.Tok == token.VAR {
+				continue
+			}
+
+			ast.Inspect(decl, func(n ast.Node) bool {
+				call, ok := n.(*ast.CallExpr)
+
+				if !ok {
+					return true
+				}
+
+				if site, ok := newSite(call, info); ok {
+					site.File = file
+					sites = append(sites, site)
+				}
+
+				return true
+			})
+		}
+	}
+
+	return sites
+}
+
+func newSite(call *ast.CallExpr, info *types.Info) (Site, bool) {
+	name := calleeName(call, info)
+
+	if name == "" || len(call.Args) == 0 || call.Ellipsis.IsValid() {
+		return Site{}, false
+	}
+
+	tv := info.Types[call.Args[0]]
+
+	if tv.Value == nil || tv.Value.Kind() != constant.String {
+		return Site{}, false
+	}
+
+	site := Site{Call: call, Func: name, Format: constant.StringVal(tv.Value)}
+
+	if name == "errors.New" {
+		if verbsIn(site.Format) {
+			site.Reason = "the message has fmt verbs, which falta.NewError does not accept"
+		} else {
+			site.Declaration = site.Format
+		}
+
+		return site, true
+	}
+
+	verbs, ok := parseVerbs(site.Format)
+
+	switch {
+	case !ok:
+		site.Reason = "the format uses explicit argument indexes or a malformed verb"
+	case len(verbs) != len(call.Args)-1:
+		site.Reason = "the format has a different number of verbs than arguments"
+	default:
+		site.Declaration, site.Args, site.Cause, site.Reason = declare(site.Format, verbs, call.Args[1:])
+	}
+
+	return site, true
+}
+
+func calleeName(call *ast.CallExpr, info *types.Info) string {
+	var obj types.Object
+
+	switch fun := call.Fun.(type) {
+	case *ast.Ident:
+		obj = info.Uses[fun]
+	case *ast.SelectorExpr:
+		obj = info.Uses[fun.Sel]
+	}
+
+	fn, ok := obj.(*types.Func)
+
+	if !ok || fn.Pkg() == nil {
+		return ""
+	}
+
+	switch name := fn.Pkg().Path() + "." + fn.Name(); name {
+	case "fmt.Errorf", "errors.New":
+		return name
+	default:
+		return ""
+	}
+}
+
+// declare splits the arguments of fmt.Errorf between the ones the declaration formats and the cause %w wraps. Since
+// Wrap puts the cause after the message, separated by ": ", only a format that ends with ": %w" can keep its wording.
+func declare(format string, verbs []verb, args []ast.Expr) (declaration string, formatted []ast.Expr, cause ast.Expr,
+	reason string) {
+	for i, v := range verbs {
+		if v.char != 'w' {
+			formatted = append(formatted, args[v.arg])
+			continue
+		}
+
+		if i != len(verbs)-1 || !strings.HasSuffix(format, ": %w") {
+			return "", nil, nil, "%w is not at the end of the format, after \": \", where Wrap puts the cause"
+		}
+
+		cause = args[v.arg]
+	}
+
+	if cause != nil {
+		format = strings.TrimSuffix(format, ": %w")
+	}
+
+	if len(formatted) == 0 {
+		format = strings.ReplaceAll(format, "%%", "%")
+
+		if verbsIn(format) {
+			return "", nil, nil, "the message has what falta.NewError takes for a verb once %% is unescaped"
+		}
+	}
+
+	return format, formatted, cause, ""
+}
+
+// verb is a verb in a format string, and the index of the argument it formats.
+type verb struct {
+	char rune
+	arg  int
+}
+
+// parseVerbs returns the verbs of a format string that consume an argument, in order. A '*' width or precision
+// consumes an argument too, and is returned with the char '*'. It reports false for formats it cannot follow, which
+// are those with explicit argument indexes, or ending in the middle of a verb.
+func parseVerbs(format string) ([]verb, bool) {
+	var verbs []verb
+	runes := []rune(format)
+
+	for i := 0; i < len(runes); i++ {
+		if runes[i] != '%' {
+			continue
+		}
+
+		i++
+
+		for i < len(runes) && strings.ContainsRune("+-# 0", runes[i]) {
+			i++
+		}
+
+		for i < len(runes) && (unicode.IsDigit(runes[i]) || runes[i] == '.' || runes[i] == '*' || runes[i] == '[') {
+			switch runes[i] {
+			case '[':
+				return nil, false
+			case '*':
+				verbs = append(verbs, verb{char: '*', arg: len(verbs)})
+			}
+
+			i++
+		}
+
+		if i == len(runes) {
+			return nil, false
+		}
+
+		if runes[i] != '%' {
+			verbs = append(verbs, verb{char: runes[i], arg: len(verbs)})
+		}
+	}
+
+	return verbs, true
+}
+
+// verbsIn reports whether s has a verb by the same rule falta.NewError panics on.
+func verbsIn(s string) bool {
+	for i := 0; i+1 < len(s); i++ {
+		if s[i] == '%' && (s[i+1] == '_' || unicode.IsLetter(rune(s[i+1])) || unicode.IsDigit(rune(s[i+1]))) {
+			return true
+		}
+	}
+
+	return false
+}
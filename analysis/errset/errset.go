@@ -0,0 +1,709 @@
+// Package errset defines an analyzer that enforces the error sets declared with falta.Set.
+//
+// A function opts in with a directive comment naming a package-level set, either of its own package or, qualified,
+// of an imported one:
+//
+//	var LoadErrors = falta.Set(ErrUserNotFound, ErrStoreClosed)
+//
+//	//falta:returns LoadErrors
+//	func Load(id int) (User, error) { ... }
+//
+// The analyzer then checks two things. Every error such a function returns must come from a member of the set: be the
+// member itself, an error built by it, or an error wrapped by it, as in ErrStoreClosed.Wrap(err). A nil error, a call
+// to another function whose set is included in this one, or a local variable only ever assigned such errors, as err is
+// by v, err := Load(id), is fine too, and so is anything when a member is deferred with Capture, either the method or
+// the function. And a falta.Match over the result of such a function must either have a Case for every member or end
+// with a Default.
+package errset
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+	"sort"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/inspect"
+	"golang.org/x/tools/go/ast/astutil"
+	"golang.org/x/tools/go/ast/inspector"
+)
+
+const faltaPath = "github.com/a20r/falta"
+
+// directive is the comment that attaches a set to a function.
+const directive = "//falta:returns "
+
+// Analyzer checks the functions that declare an error set and the falta.Match switches over their results.
+var Analyzer = &analysis.Analyzer{
+	Name:      "errset",
+	Doc:       "check that functions only return errors from their declared falta.Set, and that matches over them are exhaustive",
+	Requires:  []*analysis.Analyzer{inspect.Analyzer},
+	Run:       run,
+	FactTypes: []analysis.Fact{new(setFact), new(returnsFact)},
+}
+
+// setFact records the members of a package-level variable initialized with falta.Set.
+type setFact struct {
+	Members []string // qualified names, as returned by qualifiedName
+}
+
+func (*setFact) AFact() {}
+
+func (f *setFact) String() string {
+	return "set(" + strings.Join(f.Members, ", ") + ")"
+}
+
+// returnsFact records the set a function was declared to return errors from.
+type returnsFact struct {
+	Set     string
+	Members []string
+}
+
+func (*returnsFact) AFact() {}
+
+func (f *returnsFact) String() string {
+	return "returns(" + f.Set + ")"
+}
+
+func run(pass *analysis.Pass) (any, error) {
+	sets := collectSets(pass)
+	funcs := collectFuncs(pass, sets)
+
+	for decl, fact := range funcs {
+		checkReturns(pass, decl, fact)
+	}
+
+	checkMatches(pass)
+	return nil, nil
+}
+
+// collectSets exports a setFact for every package-level variable initialized with falta.Set.
+func collectSets(pass *analysis.Pass) map[types.Object]*setFact {
+	sets := make(map[types.Object]*setFact)
+
+	for _, file := range pass.Files {
+		for _, decl := range file.Decls {
+			gen, ok := decl.(*ast.GenDecl)
+
+			if !ok {
+				continue
+			}
+
+			for _, spec := range gen.Specs {
+				vs, ok := spec.(*ast.ValueSpec)
+
+				if !ok || len(vs.Names) != len(vs.Values) {
+					continue
+				}
+
+				for i, value := range vs.Values {
+					call, ok := value.(*ast.CallExpr)
+
+					if !ok || !isFaltaFunc(pass, call.Fun, "Set") {
+						continue
+					}
+
+					fact := &setFact{}
+
+					for _, arg := range call.Args {
+						obj := referencedObject(pass, arg)
+
+						if obj == nil {
+							pass.Reportf(arg.Pos(), "falta.Set members must be package-level factories or errors")
+							continue
+						}
+
+						fact.Members = append(fact.Members, qualifiedName(obj))
+					}
+
+					obj := pass.TypesInfo.Defs[vs.Names[i]]
+					pass.ExportObjectFact(obj, fact)
+					sets[obj] = fact
+				}
+			}
+		}
+	}
+
+	return sets
+}
+
+// collectFuncs exports a returnsFact for every function with a directive, and returns their declarations.
+func collectFuncs(pass *analysis.Pass, sets map[types.Object]*setFact) map[*ast.FuncDecl]*returnsFact {
+	funcs := make(map[*ast.FuncDecl]*returnsFact)
+
+	for _, file := range pass.Files {
+		for _, decl := range file.Decls {
+			fn, ok := decl.(*ast.FuncDecl)
+
+			if !ok || fn.Doc == nil {
+				continue
+			}
+
+			for _, comment := range fn.Doc.List {
+				name, ok := strings.CutPrefix(comment.Text, directive)
+
+				if !ok {
+					continue
+				}
+
+				name = strings.TrimSpace(name)
+				set := lookupSet(pass, file, name, sets)
+
+				if set == nil {
+					pass.Reportf(fn.Name.Pos(), "%s is not a package-level falta.Set", name)
+					continue
+				}
+
+				if errorResult(pass, fn) < 0 {
+					pass.Reportf(fn.Name.Pos(), "%s declares an error set but does not return an error", fn.Name.Name)
+					continue
+				}
+
+				fact := &returnsFact{Set: name, Members: set.Members}
+				pass.ExportObjectFact(pass.TypesInfo.Defs[fn.Name], fact)
+				funcs[fn] = fact
+			}
+		}
+	}
+
+	return funcs
+}
+
+// lookupSet resolves the set named by a directive, either in the package itself or, as pkg.Name, in an import.
+func lookupSet(pass *analysis.Pass, file *ast.File, name string, sets map[types.Object]*setFact) *setFact {
+	pkgName, setName, qualified := strings.Cut(name, ".")
+
+	if !qualified {
+		return sets[pass.Pkg.Scope().Lookup(name)]
+	}
+
+	for _, imp := range file.Imports {
+		obj, ok := pass.TypesInfo.Implicits[imp].(*types.PkgName)
+
+		if imp.Name != nil {
+			obj, ok = pass.TypesInfo.Defs[imp.Name].(*types.PkgName)
+		}
+
+		if !ok || obj.Name() != pkgName {
+			continue
+		}
+
+		fact := new(setFact)
+
+		if set := obj.Imported().Scope().Lookup(setName); set != nil && pass.ImportObjectFact(set, fact) {
+			return fact
+		}
+	}
+
+	return nil
+}
+
+// errorResult returns the index of the function's last result if it is an error, or -1.
+func errorResult(pass *analysis.Pass, fn *ast.FuncDecl) int {
+	sig := pass.TypesInfo.Defs[fn.Name].Type().(*types.Signature)
+	results := sig.Results()
+
+	if results.Len() == 0 || !types.Identical(results.At(results.Len()-1).Type(), errorType) {
+		return -1
+	}
+
+	return results.Len() - 1
+}
+
+var errorType = types.Universe.Lookup("error").Type()
+
+// checkReturns reports the return statements of fn that return an error from outside its set.
+func checkReturns(pass *analysis.Pass, fn *ast.FuncDecl, fact *returnsFact) {
+	if fn.Body == nil || capturesMember(pass, fn.Body, fact) {
+		return
+	}
+
+	index := errorResult(pass, fn)
+
+	ast.Inspect(fn.Body, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.FuncLit:
+			return false
+		case *ast.ReturnStmt:
+			switch {
+			case len(n.Results) == 0:
+				// A bare return of named results cannot be checked.
+			case len(n.Results) == 1 && index > 0:
+				if !returnsSubset(pass, n.Results[0], fact) {
+					pass.Reportf(n.Results[0].Pos(), "%s may return errors outside its set %s", fn.Name.Name, fact.Set)
+				}
+			default:
+				result := n.Results[index]
+
+				if !fromSet(pass, fn, result, fact) {
+					pass.Reportf(result.Pos(), "%s returns an error that is not from its set %s: return a member of the set, "+
+						"or wrap the error with one", fn.Name.Name, fact.Set)
+				}
+			}
+		}
+
+		return true
+	})
+}
+
+// capturesMember reports whether body defers Capture or CaptureCtx on an error rooted in a member of the set.
+func capturesMember(pass *analysis.Pass, body *ast.BlockStmt, fact *returnsFact) bool {
+	for _, stmt := range body.List {
+		deferred, ok := stmt.(*ast.DeferStmt)
+
+		if !ok {
+			continue
+		}
+
+		if factory := capturedBy(pass, deferred.Call); factory != nil && isMember(pass, rootOf(pass, factory), fact) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// fromSet reports whether the error expression is nil, built from a member of the set, returned by a function whose
+// set is included in this one, or a variable of fn that is only assigned such errors. A parameter of fn holds whatever
+// the caller passed, so it is not, while a named result starts out nil like any other variable.
+func fromSet(pass *analysis.Pass, fn *ast.FuncDecl, expr ast.Expr, fact *returnsFact) bool {
+	expr = astutil.Unparen(expr)
+
+	if ident, ok := expr.(*ast.Ident); ok {
+		if v, ok := pass.TypesInfo.Uses[ident].(*types.Var); ok && within(fn, v) {
+			isResult := fn.Type.Results != nil && within(fn.Type.Results, v)
+
+			if !within(fn.Body, v) && !isResult {
+				return false
+			}
+
+			return assignedFromSet(pass, fn.Body, v, fact)
+		}
+	}
+
+	return builtFromSet(pass, expr, fact)
+}
+
+// within reports whether v is declared in node.
+func within(node ast.Node, v *types.Var) bool {
+	return node.Pos() <= v.Pos() && v.Pos() < node.End()
+}
+
+// builtFromSet is fromSet for an expression that is not a variable of the function.
+func builtFromSet(pass *analysis.Pass, expr ast.Expr, fact *returnsFact) bool {
+	expr = astutil.Unparen(expr)
+
+	if tv, ok := pass.TypesInfo.Types[expr]; ok && tv.IsNil() {
+		return true
+	}
+
+	if isMember(pass, rootOf(pass, expr), fact) {
+		return true
+	}
+
+	return returnsSubset(pass, expr, fact)
+}
+
+// assignedFromSet reports whether every value body assigns to the local variable v is nil, built from a member of the
+// set, or the error result of a function whose set is included in this one. A variable whose address is taken may be
+// assigned anything, so it is not.
+func assignedFromSet(pass *analysis.Pass, body *ast.BlockStmt, v *types.Var, fact *returnsFact) bool {
+	isV := func(expr ast.Expr) bool {
+		id, ok := astutil.Unparen(expr).(*ast.Ident)
+		return ok && (pass.TypesInfo.Defs[id] == v || pass.TypesInfo.Uses[id] == v)
+	}
+
+	// assigned checks the value assigned to the i-th of n variables from values.
+	assigned := func(i, n int, values []ast.Expr) bool {
+		switch {
+		case len(values) == 0:
+			return true // declared without a value, so nil
+		case len(values) == n:
+			return builtFromSet(pass, values[i], fact)
+		default:
+			return len(values) == 1 && i == n-1 && returnsSubset(pass, values[0], fact)
+		}
+	}
+
+	ok := true
+
+	ast.Inspect(body, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.AssignStmt:
+			for i, lhs := range n.Lhs {
+				if isV(lhs) && !assigned(i, len(n.Lhs), n.Rhs) {
+					ok = false
+				}
+			}
+		case *ast.ValueSpec:
+			for i, name := range n.Names {
+				if isV(name) && !assigned(i, len(n.Names), n.Values) {
+					ok = false
+				}
+			}
+		case *ast.RangeStmt:
+			if (n.Key != nil && isV(n.Key)) || (n.Value != nil && isV(n.Value)) {
+				ok = false
+			}
+		case *ast.UnaryExpr:
+			if n.Op == token.AND && isV(n.X) {
+				ok = false
+			}
+		}
+
+		return ok
+	})
+
+	return ok
+}
+
+// returnsSubset reports whether expr calls a function whose declared set is included in fact's.
+func returnsSubset(pass *analysis.Pass, expr ast.Expr, fact *returnsFact) bool {
+	call, ok := astutil.Unparen(expr).(*ast.CallExpr)
+
+	if !ok {
+		return false
+	}
+
+	callee := calledFunc(pass, call)
+	other := new(returnsFact)
+
+	if callee == nil || !pass.ImportObjectFact(callee, other) {
+		return false
+	}
+
+	for _, member := range other.Members {
+		if !contains(fact.Members, member) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// rootOf strips the falta method calls from an expression such as ErrX.New(1).Annotate("a").Wrap(err) and returns
+// what they were called on, ErrX. A call to falta.NewCtx(ctx, ErrX, 1) is stripped to the factory it builds with.
+func rootOf(pass *analysis.Pass, expr ast.Expr) ast.Expr {
+	for {
+		expr = astutil.Unparen(expr)
+		call, ok := expr.(*ast.CallExpr)
+
+		if !ok {
+			return expr
+		}
+
+		if factory := builtBy(pass, call); factory != nil {
+			expr = factory
+			continue
+		}
+
+		sel, ok := call.Fun.(*ast.SelectorExpr)
+
+		if !ok {
+			return expr
+		}
+
+		selection, ok := pass.TypesInfo.Selections[sel]
+
+		if !ok || selection.Kind() != types.MethodVal || selection.Obj().Pkg() == nil ||
+			selection.Obj().Pkg().Path() != faltaPath {
+			return expr
+		}
+
+		expr = sel.X
+	}
+}
+
+// capturedBy returns what a call to Capture or CaptureCtx wraps the error with: the receiver of the method, or the
+// factory argument of the function. It returns nil for any other call.
+func capturedBy(pass *analysis.Pass, call *ast.CallExpr) ast.Expr {
+	fn := calledFunc(pass, call)
+
+	if fn == nil || fn.Name() != "Capture" && fn.Name() != "CaptureCtx" {
+		return nil
+	}
+
+	if fn.Type().(*types.Signature).Recv() != nil {
+		return astutil.Unparen(call.Fun).(*ast.SelectorExpr).X
+	}
+
+	if fn.Pkg() == nil || fn.Pkg().Path() != faltaPath {
+		return nil
+	}
+
+	// The function takes the context first, if any, then the error, then the factory.
+	index := 1
+
+	if fn.Name() == "CaptureCtx" {
+		index = 2
+	}
+
+	if index >= len(call.Args) {
+		return nil
+	}
+
+	return call.Args[index]
+}
+
+// builtBy returns the factory argument of a call to falta.NewCtx, or nil if call is not one.
+func builtBy(pass *analysis.Pass, call *ast.CallExpr) ast.Expr {
+	if !isFaltaFunc(pass, call.Fun, "NewCtx") || len(call.Args) < 2 {
+		return nil
+	}
+
+	return call.Args[1]
+}
+
+func isMember(pass *analysis.Pass, expr ast.Expr, fact *returnsFact) bool {
+	obj := referencedObject(pass, expr)
+	return obj != nil && contains(fact.Members, qualifiedName(obj))
+}
+
+// referencedObject returns the package-level variable an expression such as ErrX or pkg.ErrX refers to.
+func referencedObject(pass *analysis.Pass, expr ast.Expr) types.Object {
+	var obj types.Object
+
+	switch e := astutil.Unparen(expr).(type) {
+	case *ast.Ident:
+		obj = pass.TypesInfo.Uses[e]
+	case *ast.SelectorExpr:
+		obj = pass.TypesInfo.Uses[e.Sel]
+	}
+
+	v, ok := obj.(*types.Var)
+
+	if !ok || v.Pkg() == nil || v.Parent() != v.Pkg().Scope() {
+		return nil
+	}
+
+	return v
+}
+
+func qualifiedName(obj types.Object) string {
+	return obj.Pkg().Path() + "." + obj.Name()
+}
+
+func calledFunc(pass *analysis.Pass, call *ast.CallExpr) *types.Func {
+	var obj types.Object
+
+	switch fun := astutil.Unparen(call.Fun).(type) {
+	case *ast.Ident:
+		obj = pass.TypesInfo.Uses[fun]
+	case *ast.SelectorExpr:
+		obj = pass.TypesInfo.Uses[fun.Sel]
+	}
+
+	fn, _ := obj.(*types.Func)
+	return fn
+}
+
+func isFaltaFunc(pass *analysis.Pass, fun ast.Expr, name string) bool {
+	sel, ok := astutil.Unparen(fun).(*ast.SelectorExpr)
+
+	if !ok {
+		return false
+	}
+
+	fn, ok := pass.TypesInfo.Uses[sel.Sel].(*types.Func)
+	return ok && fn.Pkg() != nil && fn.Pkg().Path() == faltaPath && fn.Name() == name
+}
+
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+
+	return false
+}
+
+// checkMatches reports falta.Match switches over the result of a function with a declared set that neither handle
+// every member nor have a Default.
+func checkMatches(pass *analysis.Pass) {
+	insp := pass.ResultOf[inspect.Analyzer].(*inspector.Inspector)
+
+	insp.WithStack([]ast.Node{(*ast.CallExpr)(nil)}, func(n ast.Node, push bool, stack []ast.Node) bool {
+		call := n.(*ast.CallExpr)
+
+		if !push || !isFaltaFunc(pass, call.Fun, "Match") || len(call.Args) != 1 {
+			return true
+		}
+
+		fact := matchedSet(pass, call.Args[0], stack)
+
+		if fact == nil {
+			return true
+		}
+
+		cases, hasDefault, complete := climbChain(pass, call, stack)
+
+		if !complete || hasDefault {
+			return true
+		}
+
+		var missing []string
+
+		for _, member := range fact.Members {
+			if !contains(cases, member) {
+				missing = append(missing, member[strings.LastIndex(member, "/")+1:])
+			}
+		}
+
+		if len(missing) > 0 {
+			sort.Strings(missing)
+			pass.Reportf(call.Pos(), "falta.Match over %s is not exhaustive: missing %s; add the cases or a Default",
+				fact.Set, strings.Join(missing, ", "))
+		}
+
+		return true
+	})
+}
+
+// climbChain follows the Case, CaseData and Default calls made on a Match call, returning the members they handle and
+// whether one is a Default. The chain is complete if its result is discarded, as it is when it ends with Default; if
+// it is stored instead, more cases may be added later and it cannot be checked.
+func climbChain(pass *analysis.Pass, call *ast.CallExpr, stack []ast.Node) (cases []string, hasDefault, complete bool) {
+	current := ast.Node(call)
+
+	for i := len(stack) - 2; i >= 0; i-- {
+		switch parent := stack[i].(type) {
+		case *ast.ParenExpr:
+			current = parent
+		case *ast.SelectorExpr:
+			if parent.X != current || i == 0 {
+				return cases, hasDefault, false
+			}
+
+			outer, ok := stack[i-1].(*ast.CallExpr)
+
+			if !ok || outer.Fun != parent {
+				return cases, hasDefault, false
+			}
+
+			switch parent.Sel.Name {
+			case "Case":
+				cases = appendCase(pass, cases, outer.Args)
+			case "Default":
+				hasDefault = true
+			}
+
+			current = outer
+			i--
+		case *ast.CallExpr:
+			if !isFaltaFunc(pass, parent.Fun, "CaseData") || len(parent.Args) < 2 || parent.Args[0] != current {
+				return cases, hasDefault, false
+			}
+
+			cases = appendCase(pass, cases, parent.Args[1:])
+			current = parent
+		case *ast.ExprStmt:
+			return cases, hasDefault, true
+		default:
+			return cases, hasDefault, false
+		}
+	}
+
+	return cases, hasDefault, false
+}
+
+func appendCase(pass *analysis.Pass, cases []string, args []ast.Expr) []string {
+	if len(args) == 0 {
+		return cases
+	}
+
+	if obj := referencedObject(pass, args[0]); obj != nil {
+		cases = append(cases, qualifiedName(obj))
+	}
+
+	return cases
+}
+
+// matchedSet returns the set of the function whose result a Match switches over: either a direct call to it, or a
+// variable that the enclosing function only assigns from calls to functions with the same set.
+func matchedSet(pass *analysis.Pass, arg ast.Expr, stack []ast.Node) *returnsFact {
+	arg = astutil.Unparen(arg)
+
+	if call, ok := arg.(*ast.CallExpr); ok {
+		return funcSet(pass, call)
+	}
+
+	ident, ok := arg.(*ast.Ident)
+
+	if !ok {
+		return nil
+	}
+
+	obj := pass.TypesInfo.Uses[ident]
+	body := enclosingBody(stack)
+
+	if obj == nil || body == nil {
+		return nil
+	}
+
+	var found *returnsFact
+	consistent := true
+
+	ast.Inspect(body, func(n ast.Node) bool {
+		assign, ok := n.(*ast.AssignStmt)
+
+		if !ok {
+			return true
+		}
+
+		for i, lhs := range assign.Lhs {
+			id, ok := lhs.(*ast.Ident)
+
+			if !ok || (pass.TypesInfo.Defs[id] != obj && pass.TypesInfo.Uses[id] != obj) {
+				continue
+			}
+
+			var fact *returnsFact
+
+			if len(assign.Rhs) == 1 {
+				if call, ok := astutil.Unparen(assign.Rhs[0]).(*ast.CallExpr); ok && i == len(assign.Lhs)-1 {
+					fact = funcSet(pass, call)
+				}
+			}
+
+			if fact == nil || (found != nil && found.Set != fact.Set) {
+				consistent = false
+			}
+
+			found = fact
+		}
+
+		return true
+	})
+
+	if !consistent {
+		return nil
+	}
+
+	return found
+}
+
+func funcSet(pass *analysis.Pass, call *ast.CallExpr) *returnsFact {
+	callee := calledFunc(pass, call)
+	fact := new(returnsFact)
+
+	if callee == nil || !pass.ImportObjectFact(callee, fact) {
+		return nil
+	}
+
+	return fact
+}
+
+func enclosingBody(stack []ast.Node) *ast.BlockStmt {
+	for i := len(stack) - 1; i >= 0; i-- {
+		switch n := stack[i].(type) {
+		case *ast.FuncDecl:
+			return n.Body
+		case *ast.FuncLit:
+			return n.Body
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,443 @@
+// Package factories finds the falta factories of a module that nothing uses, and those that collide.
+//
+// A factory is unused when no code builds an error with it: it is never instantiated with New, wrapped, annotated or
+// returned, only perhaps matched against, which always fails. Two factories collide when they are declared with the
+// same string, since falta matches errors by declaration: errors.Is then reports an error built by one as the other.
+//
+// Both need to see the whole module at once, since an exported factory may be used, or redeclared, by any package in
+// it, so Check takes every package. The faltafactories command runs it.
+package factories
+
+import (
+	"fmt"
+	"go/ast"
+	"go/constant"
+	"go/token"
+	"go/types"
+	"sort"
+	"strings"
+
+	"golang.org/x/tools/go/ast/astutil"
+)
+
+const faltaPath = "github.com/a20r/falta"
+
+// Package is a parsed and type-checked package.
+type Package struct {
+	Fset  *token.FileSet
+	Files []*ast.File
+	Info  *types.Info
+}
+
+// Problem is an unused or colliding factory.
+type Problem struct {
+	Pos     token.Position
+	Message string
+}
+
+// Factory is a package-level factory declaration.
+type Factory struct {
+	// Name is the variable the factory is declared as, qualified by its package path.
+	Name string
+	Pos  token.Position
+
+	// Declaration is what the factory is declared with, including the prefix of its namespace, or empty if it could
+	// not be worked out, as when the namespace is not a package-level variable.
+	Declaration string
+}
+
+// Check returns the unused and colliding factories declared in pkgs, sorted by position. A package may be passed more
+// than once, as it is with the variant of it compiled for its tests, and its factories are only reported once.
+func Check(pkgs []Package) []Problem {
+	inits := initializers(pkgs)
+	names := make(map[string]bool)
+	var declared []Factory
+
+	for _, pkg := range pkgs {
+		for _, f := range Declared(pkg, inits) {
+			if !names[f.Name] {
+				names[f.Name] = true
+				declared = append(declared, f)
+			}
+		}
+	}
+
+	produced := make(map[string]bool)
+
+	for _, pkg := range pkgs {
+		for name := range producingUses(pkg, names) {
+			produced[name] = true
+		}
+	}
+
+	var problems []Problem
+
+	for _, f := range declared {
+		if !produced[f.Name] {
+			problems = append(problems, Problem{Pos: f.Pos, Message: fmt.Sprintf("%s is never instantiated or "+
+				"returned: no code builds an error with it, so matching against it always fails; remove it", f.Name)})
+		}
+	}
+
+	problems = append(problems, collisions(declared)...)
+
+	sort.SliceStable(problems, func(i, j int) bool {
+		a, b := problems[i].Pos, problems[j].Pos
+
+		if a.Filename != b.Filename {
+			return a.Filename < b.Filename
+		}
+
+		return a.Offset < b.Offset
+	})
+
+	return problems
+}
+
+// collisions reports each factory whose declaration is also the declaration of another.
+func collisions(declared []Factory) []Problem {
+	byDeclaration := make(map[string][]Factory)
+
+	for _, f := range declared {
+		if f.Declaration != "" {
+			byDeclaration[f.Declaration] = append(byDeclaration[f.Declaration], f)
+		}
+	}
+
+	var problems []Problem
+
+	for _, f := range declared {
+		var others []string
+
+		for _, other := range byDeclaration[f.Declaration] {
+			if other.Name != f.Name {
+				others = append(others, fmt.Sprintf("%s (%s)", other.Name, other.Pos))
+			}
+		}
+
+		if f.Declaration == "" || len(others) == 0 {
+			continue
+		}
+
+		problems = append(problems, Problem{Pos: f.Pos, Message: fmt.Sprintf("%s is declared as %q, like %s: falta "+
+			"matches errors by declaration, so errors.Is(err, %s) also reports the errors they build, and they report "+
+			"its; reword it, or declare them in different namespaces", f.Name, f.Declaration,
+			strings.Join(others, " and "), shortName(f.Name))})
+	}
+
+	return problems
+}
+
+// initializers maps the package-level variables of pkgs to the expressions they are initialized with.
+func initializers(pkgs []Package) map[types.Object]ast.Expr {
+	inits := make(map[types.Object]ast.Expr)
+
+	for _, pkg := range pkgs {
+		for _, file := range pkg.Files {
+			for _, decl := range file.Decls {
+				gen, ok := decl.(*ast.GenDecl)
+
+				if !ok || gen.Tok != token.VAR {
+					continue
+				}
+
+				for _, spec := range gen.Specs {
+					vs := spec.(*ast.ValueSpec)
+
+					if len(vs.Names) != len(vs.Values) {
+						continue
+					}
+
+					for i, name := range vs.Names {
+						if obj := pkg.Info.Defs[name]; obj != nil {
+							inits[obj] = vs.Values[i]
+						}
+					}
+				}
+			}
+		}
+	}
+
+	return inits
+}
+
+// Declared returns the factories declared by pkg. The initializers of package-level variables are used to work out
+// the prefixes of namespaces.
+func Declared(pkg Package, inits map[types.Object]ast.Expr) []Factory {
+	var factories []Factory
+
+	for _, file := range pkg.Files {
+		for _, decl := range file.Decls {
+			gen, ok := decl.(*ast.GenDecl)
+
+			if !ok || gen.Tok != token.VAR {
+				continue
+			}
+
+			for _, spec := range gen.Specs {
+				vs := spec.(*ast.ValueSpec)
+
+				if len(vs.Names) != len(vs.Values) {
+					continue
+				}
+
+				for i, name := range vs.Names {
+					call, ok := astutil.Unparen(vs.Values[i]).(*ast.CallExpr)
+					obj := pkg.Info.Defs[name]
+
+					if !ok || obj == nil || name.Name == "_" {
+						continue
+					}
+
+					declaration, ok := declarationOf(pkg.Info, call, inits)
+
+					if !ok {
+						continue
+					}
+
+					factories = append(factories, Factory{
+						Name:        qualifiedName(obj),
+						Pos:         pkg.Fset.Position(name.Pos()),
+						Declaration: declaration,
+					})
+				}
+			}
+		}
+	}
+
+	return factories
+}
+
+// declarationOf reports whether call declares a factory, and returns its declaration if it can be worked out.
+func declarationOf(info *types.Info, call *ast.CallExpr, inits map[types.Object]ast.Expr) (string, bool) {
+	fn, recv := faltaFunc(info, call.Fun)
+	var format ast.Expr
+	var scope ast.Expr
+
+	switch {
+	case recv == "" && (fn == "Newf" || fn == "New" || fn == "NewM" || fn == "NewError"):
+		if len(call.Args) == 0 {
+			return "", true
+		}
+
+		format = call.Args[0]
+	case recv == "" && fn == "NewIn":
+		if len(call.Args) < 2 {
+			return "", true
+		}
+
+		scope, format = call.Args[0], call.Args[1]
+	case recv == "Scope" && (fn == "Newf" || fn == "NewM" || fn == "NewError"):
+		if len(call.Args) == 0 {
+			return "", true
+		}
+
+		scope, format = astutil.Unparen(call.Fun).(*ast.SelectorExpr).X, call.Args[0]
+	default:
+		return "", false
+	}
+
+	tv := info.Types[format]
+
+	if tv.Value == nil || tv.Value.Kind() != constant.String {
+		return "", true
+	}
+
+	declaration := constant.StringVal(tv.Value)
+
+	if scope != nil {
+		names, ok := scopeNames(info, scope, inits, 0)
+
+		if !ok {
+			return "", true
+		}
+
+		prefix := strings.Join(names, ": ") + ": "
+
+		// Like falta's Scope.Newf, a printf declaration escapes the percent signs of its prefix, so they are not verbs.
+		if fn == "Newf" {
+			prefix = strings.ReplaceAll(prefix, "%", "%%")
+		}
+
+		declaration = prefix + declaration
+	}
+
+	return declaration, true
+}
+
+// scopeNames returns the names of the namespaces a scope expression is nested in, outermost first.
+func scopeNames(info *types.Info, expr ast.Expr, inits map[types.Object]ast.Expr, depth int) ([]string, bool) {
+	if depth > 16 {
+		return nil, false
+	}
+
+	switch e := astutil.Unparen(expr).(type) {
+	case *ast.Ident:
+		if init, ok := inits[info.Uses[e]]; ok {
+			return scopeNames(info, init, inits, depth+1)
+		}
+	case *ast.SelectorExpr:
+		if init, ok := inits[info.Uses[e.Sel]]; ok {
+			return scopeNames(info, init, inits, depth+1)
+		}
+	case *ast.CallExpr:
+		fn, recv := faltaFunc(info, e.Fun)
+
+		if fn != "Namespace" || len(e.Args) == 0 {
+			return nil, false
+		}
+
+		tv := info.Types[e.Args[0]]
+
+		if tv.Value == nil || tv.Value.Kind() != constant.String {
+			return nil, false
+		}
+
+		name := constant.StringVal(tv.Value)
+
+		if recv == "" {
+			return []string{name}, true
+		}
+
+		outer, ok := scopeNames(info, astutil.Unparen(e.Fun).(*ast.SelectorExpr).X, inits, depth+1)
+
+		if !ok {
+			return nil, false
+		}
+
+		return append(outer, name), true
+	}
+
+	return nil, false
+}
+
+// faltaFunc returns the name of the falta function or method fun refers to, and the name of the method's receiver
+// type, or empty strings if it is not one.
+func faltaFunc(info *types.Info, fun ast.Expr) (name, recv string) {
+	fun = astutil.Unparen(fun)
+
+	switch f := fun.(type) {
+	case *ast.IndexExpr:
+		fun = f.X
+	case *ast.IndexListExpr:
+		fun = f.X
+	}
+
+	var obj types.Object
+
+	switch f := fun.(type) {
+	case *ast.Ident:
+		obj = info.Uses[f]
+	case *ast.SelectorExpr:
+		obj = info.Uses[f.Sel]
+	}
+
+	fn, ok := obj.(*types.Func)
+
+	if !ok || fn.Pkg() == nil || fn.Pkg().Path() != faltaPath {
+		return "", ""
+	}
+
+	if sig := fn.Type().(*types.Signature); sig.Recv() != nil {
+		t := sig.Recv().Type()
+
+		if ptr, ok := t.(*types.Pointer); ok {
+			t = ptr.Elem()
+		}
+
+		if named, ok := t.(*types.Named); ok {
+			recv = named.Obj().Name()
+		}
+	}
+
+	return fn.Name(), recv
+}
+
+// producingUses returns the names of the factories among declared that pkg uses other than to match errors against: as
+// the target of errors.Is, of a Case or CaseData, or as a member of a falta.Set.
+func producingUses(pkg Package, declared map[string]bool) map[string]bool {
+	matching := make(map[*ast.Ident]bool)
+
+	for _, file := range pkg.Files {
+		ast.Inspect(file, func(n ast.Node) bool {
+			call, ok := n.(*ast.CallExpr)
+
+			if !ok {
+				return true
+			}
+
+			for _, arg := range matchingArgs(pkg.Info, call) {
+				if id := identOf(arg); id != nil {
+					matching[id] = true
+				}
+			}
+
+			return true
+		})
+	}
+
+	produced := make(map[string]bool)
+
+	for id, obj := range pkg.Info.Uses {
+		v, ok := obj.(*types.Var)
+
+		if !ok || v.Pkg() == nil || v.Parent() != v.Pkg().Scope() || matching[id] {
+			continue
+		}
+
+		if name := qualifiedName(v); declared[name] {
+			produced[name] = true
+		}
+	}
+
+	return produced
+}
+
+// matchingArgs returns the arguments of call that are matched against rather than built or returned.
+func matchingArgs(info *types.Info, call *ast.CallExpr) []ast.Expr {
+	if fn, _ := faltaFunc(info, call.Fun); fn != "" {
+		switch {
+		case fn == "Set":
+			return call.Args
+		case fn == "Case" && len(call.Args) > 0:
+			return call.Args[:1]
+		case fn == "CaseData" && len(call.Args) > 1:
+			return call.Args[1:2]
+		}
+
+		return nil
+	}
+
+	var obj types.Object
+
+	if sel, ok := astutil.Unparen(call.Fun).(*ast.SelectorExpr); ok {
+		obj = info.Uses[sel.Sel]
+	}
+
+	if fn, ok := obj.(*types.Func); ok && fn.Pkg() != nil && fn.Pkg().Path() == "errors" && fn.Name() == "Is" &&
+		len(call.Args) == 2 {
+		return call.Args[1:]
+	}
+
+	return nil
+}
+
+func identOf(expr ast.Expr) *ast.Ident {
+	switch e := astutil.Unparen(expr).(type) {
+	case *ast.Ident:
+		return e
+	case *ast.SelectorExpr:
+		return e.Sel
+	}
+
+	return nil
+}
+
+func qualifiedName(obj types.Object) string {
+	return obj.Pkg().Path() + "." + obj.Name()
+}
+
+// shortName returns a qualified name with only the last element of its package path, as in store.ErrClosed.
+func shortName(name string) string {
+	return name[strings.LastIndex(name, "/")+1:]
+}
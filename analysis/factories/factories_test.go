@@ -0,0 +1,146 @@
+package factories_test
+
+import (
+	"fmt"
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/a20r/falta/analysis/factories"
+	"github.com/stretchr/testify/assert"
+)
+
+// loader type-checks the packages of the testdata directory, and the standard library from export data.
+type loader struct {
+	fset *token.FileSet
+	std  types.Importer
+	pkgs map[string]*types.Package
+	all  []factories.Package
+}
+
+func (l *loader) Import(path string) (*types.Package, error) {
+	if pkg, ok := l.pkgs[path]; ok {
+		return pkg, nil
+	}
+
+	dir := filepath.Join("..", "testdata", "src", path)
+
+	if _, err := os.Stat(dir); err != nil {
+		return l.std.Import(path)
+	}
+
+	entries, err := os.ReadDir(dir)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var files []*ast.File
+
+	for _, entry := range entries {
+		if !strings.HasSuffix(entry.Name(), ".go") {
+			continue
+		}
+
+		file, err := parser.ParseFile(l.fset, filepath.Join(dir, entry.Name()), nil, 0)
+
+		if err != nil {
+			return nil, err
+		}
+
+		files = append(files, file)
+	}
+
+	info := &types.Info{
+		Types: make(map[ast.Expr]types.TypeAndValue),
+		Defs:  make(map[*ast.Ident]types.Object),
+		Uses:  make(map[*ast.Ident]types.Object),
+	}
+
+	pkg, err := (&types.Config{Importer: l}).Check(path, l.fset, files, info)
+
+	if err != nil {
+		return nil, fmt.Errorf("checking %s: %w", path, err)
+	}
+
+	l.pkgs[path] = pkg
+	l.all = append(l.all, factories.Package{Fset: l.fset, Files: files, Info: info})
+
+	return pkg, nil
+}
+
+func TestCheck(t *testing.T) {
+	as := assert.New(t)
+
+	l := &loader{fset: token.NewFileSet(), std: importer.Default(), pkgs: make(map[string]*types.Package)}
+
+	_, err := l.Import("factories/b")
+	as.NoError(err)
+
+	// Passing a package twice, as the faltafactories command does with test variants, reports nothing more.
+	var problems []string
+
+	for _, problem := range factories.Check(append(l.all, l.all...)) {
+		problems = append(problems, fmt.Sprintf("%s:%d: %s", filepath.Base(problem.Pos.Filename), problem.Pos.Line,
+			problem.Message))
+	}
+
+	as.Equal([]string{
+		"a.go:13: factories/a.ErrMatchedOnly is never instantiated or returned: no code builds an error with it, " +
+			"so matching against it always fails; remove it",
+		"a.go:14: factories/a.errUnused is never instantiated or returned: no code builds an error with it, " +
+			"so matching against it always fails; remove it",
+		`a.go:15: factories/a.ErrScoped is declared as "store: closed", like factories/b.ErrStoreClosed (` +
+			problemPos(l, "b", 11) + `): falta matches errors by declaration, so errors.Is(err, a.ErrScoped) also ` +
+			"reports the errors they build, and they report its; reword it, or declare them in different namespaces",
+		`a.go:16: factories/a.ErrDuplicate is declared as "duplicate", like factories/b.ErrDuplicate (` +
+			problemPos(l, "b", 10) + `): falta matches errors by declaration, so errors.Is(err, a.ErrDuplicate) ` +
+			"also reports the errors they build, and they report its; reword it, or declare them in different " +
+			"namespaces",
+		`a.go:17: factories/a.ErrNested is declared as "store: cache: miss for %s", like factories/b.ErrCacheMiss (` +
+			problemPos(l, "b", 12) + `): falta matches errors by declaration, so errors.Is(err, a.ErrNested) also ` +
+			"reports the errors they build, and they report its; reword it, or declare them in different namespaces",
+		"b.go:10: factories/b.ErrDuplicate is never instantiated or returned: no code builds an error with it, " +
+			"so matching against it always fails; remove it",
+		`b.go:10: factories/b.ErrDuplicate is declared as "duplicate", like factories/a.ErrDuplicate (` +
+			problemPos(l, "a", 16) + `): falta matches errors by declaration, so errors.Is(err, b.ErrDuplicate) ` +
+			"also reports the errors they build, and they report its; reword it, or declare them in different " +
+			"namespaces",
+		`b.go:11: factories/b.ErrStoreClosed is declared as "store: closed", like factories/a.ErrScoped (` +
+			problemPos(l, "a", 15) + `): falta matches errors by declaration, so errors.Is(err, b.ErrStoreClosed) ` +
+			"also reports the errors they build, and they report its; reword it, or declare them in different " +
+			"namespaces",
+		"b.go:12: factories/b.ErrCacheMiss is never instantiated or returned: no code builds an error with it, " +
+			"so matching against it always fails; remove it",
+		`b.go:12: factories/b.ErrCacheMiss is declared as "store: cache: miss for %s", like factories/a.ErrNested (` +
+			problemPos(l, "a", 17) + `): falta matches errors by declaration, so errors.Is(err, b.ErrCacheMiss) ` +
+			"also reports the errors they build, and they report its; reword it, or declare them in different " +
+			"namespaces",
+	}, problems)
+}
+
+func TestCheck_PercentInNamespace(t *testing.T) {
+	as := assert.New(t)
+	l := &loader{fset: token.NewFileSet(), std: importer.Default(), pkgs: make(map[string]*types.Package)}
+
+	_, err := l.Import("factories/c")
+	as.NoError(err)
+
+	problems := factories.Check(l.all)
+
+	// Scope.Newf escapes the percent sign of the namespace, so the two declarations are the same printf format.
+	if as.Len(problems, 2) {
+		as.Contains(problems[0].Message, `is declared as "disk 100%%: full"`)
+	}
+}
+
+// problemPos returns the position of the factory declared on line of the package's only file, as Check prints it.
+func problemPos(l *loader, pkg string, line int) string {
+	return fmt.Sprintf("%s:%d:2", filepath.Join("..", "testdata", "src", "factories", pkg, pkg+".go"), line)
+}
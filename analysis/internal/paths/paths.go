@@ -0,0 +1,23 @@
+// Package paths holds the path helpers shared by the analysis commands.
+package paths
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Relative returns path relative to the working directory, if it is in it.
+func Relative(path string) string {
+	wd, err := os.Getwd()
+
+	if err != nil {
+		return path
+	}
+
+	if rel, err := filepath.Rel(wd, path); err == nil && !strings.HasPrefix(rel, "..") {
+		return rel
+	}
+
+	return path
+}
@@ -0,0 +1,20 @@
+package paths_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/a20r/falta/analysis/internal/paths"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRelative(t *testing.T) {
+	as := assert.New(t)
+
+	wd, err := os.Getwd()
+	as.NoError(err)
+
+	as.Equal(filepath.Join("a", "b.go:3:1"), paths.Relative(filepath.Join(wd, "a", "b.go:3:1")))
+	as.Equal("/elsewhere/b.go", paths.Relative("/elsewhere/b.go"), "paths outside the working directory are kept")
+}
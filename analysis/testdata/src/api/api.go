@@ -0,0 +1,61 @@
+package api
+
+import (
+	"errors"
+
+	"github.com/a20r/falta"
+	"store"
+)
+
+//falta:returns store.LoadErrors
+func Get(id int) (string, error) { // want Get:`returns\(store.LoadErrors\)`
+	if id < 0 {
+		return "", errors.New("negative id") // want `Get returns an error that is not from its set store.LoadErrors`
+	}
+
+	return store.Load(id)
+}
+
+//falta:returns store.ClosedOnly
+func Shutdown() error { // want Shutdown:`returns\(store.ClosedOnly\)`
+	return store.ErrClosed
+}
+
+func Exhaustive() {
+	_, err := Get(1)
+
+	falta.Match(err).
+		Case(store.ErrClosed, func(falta.Falta) {}).
+		Case(store.ErrNotFound, func(falta.Falta) {})
+}
+
+func WithData() {
+	_, err := Get(1)
+
+	falta.CaseData(falta.Match(err), store.ErrNotFound, func(any, falta.Falta) {}).
+		Case(store.ErrClosed, func(falta.Falta) {})
+}
+
+func WithDefault() {
+	_, err := Get(1)
+
+	falta.Match(err).
+		Case(store.ErrClosed, func(falta.Falta) {}).
+		Default(func(error) {})
+}
+
+func Missing() {
+	falta.Match(Shutdown()) // want `falta.Match over store.ClosedOnly is not exhaustive: missing store.ErrClosed; add the cases or a Default`
+}
+
+func Stored() {
+	m := falta.Match(Shutdown())
+	m.Default(func(error) {})
+}
+
+func Reassigned() {
+	_, err := Get(1)
+	err = errors.New("other")
+
+	falta.Match(err)
+}
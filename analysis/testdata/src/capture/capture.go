@@ -0,0 +1,125 @@
+package capture
+
+import (
+	"context"
+	"errors"
+
+	"github.com/a20r/falta"
+)
+
+var (
+	errLoad  = falta.Newf("cannot load user %d")
+	errStore = falta.NewError("store failed")
+)
+
+func deferred(id int) (_ string, err error) {
+	defer errLoad.New(id).Capture(&err)
+
+	return "", errors.New("disk")
+}
+
+func deferredCtx(ctx context.Context, id int) (err error) {
+	defer errLoad.New(id).CaptureCtx(ctx, &err)
+
+	return errors.New("disk")
+}
+
+func literal(id int) (_ string, err error) {
+	defer func() {
+		if id > 0 {
+			errLoad.New(id).Capture(&err)
+		}
+	}()
+
+	id++
+
+	return "", errors.New("disk")
+}
+
+func notDeferred() (err error) {
+	err = errors.New("disk")
+	errStore.Capture(&err) // want `Capture is not deferred, so it wraps the error before the function returns it, if at all; call it as defer ...Capture\(&err\) at the top of the function`
+
+	return err
+}
+
+func notDeferredLiteral() (err error) {
+	wrap := func() {
+		errStore.Capture(&err) // want `Capture is not deferred`
+	}
+
+	wrap()
+	return err
+}
+
+func local() (err error) {
+	var other error
+	defer errStore.Capture(&other) // want `Capture must be passed &err, the function's named error result, so it wraps the error the function returns`
+
+	return errors.New("disk")
+}
+
+func unnamed() error {
+	var err error
+	defer errStore.Capture(&err) // want `Capture is used in a function without a named error result, so it cannot change the error the function returns; name the result, as in \(_ T, err error\)`
+
+	return err
+}
+
+func blank() (_ error) {
+	var err error
+	defer errStore.Capture(&err) // want `Capture is used in a function without a named error result`
+
+	return err
+}
+
+func stale(ctx context.Context) (err error) {
+	id := 0
+	defer errLoad.New(id).Annotatef("attempt %d", id).CaptureCtx(ctx, &err) // want `id is evaluated when Capture is deferred, but is assigned again on line 86, so the error will have its old value; wrap the Capture in a deferred function literal`
+
+	for i := 0; i < 3; i++ {
+		if i == 2 {
+			return errors.New("disk")
+		}
+	}
+
+	id = 42
+
+	return nil
+}
+
+func settled(id int) (err error) {
+	id++
+	defer errLoad.New(id).Capture(&err)
+
+	return errors.New("disk")
+}
+
+func deferredFunc(ctx context.Context, id int) (err error) {
+	defer falta.Capture(&err, errLoad, id)
+	defer falta.CaptureCtx(ctx, &err, errLoad, id)
+
+	return errors.New("disk")
+}
+
+func notDeferredFunc(id int) (err error) {
+	err = errors.New("disk")
+	falta.Capture(&err, errLoad, id) // want `Capture is not deferred`
+
+	return err
+}
+
+func localFunc(ctx context.Context, id int) (err error) {
+	var other error
+	defer falta.CaptureCtx(ctx, &other, errLoad, id) // want `CaptureCtx must be passed &err`
+
+	return errors.New("disk")
+}
+
+func staleFunc(id int) (err error) {
+	defer falta.Capture(&err, errLoad, id) // want `id is evaluated when Capture is deferred, but is assigned again on line 122`
+
+	id = 42
+
+	return errors.New("disk")
+}
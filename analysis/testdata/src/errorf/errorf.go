@@ -0,0 +1,27 @@
+package errorf
+
+import (
+	"errors"
+	"fmt"
+)
+
+var errSentinel = errors.New("not ad hoc")
+
+func load(id int, err error) error {
+	switch id {
+	case 0:
+		return fmt.Errorf("cannot load user %d: %w", id, err) // want `fmt.Errorf "cannot load user %d: %w" is worded the same as other ad-hoc errors in the package \("Cannot  load user %v" on line 15\); declare one falta factory for them, which faltamigrate -fix does`
+	case 1:
+		return fmt.Errorf("Cannot  load user %v", id) // want `fmt.Errorf "Cannot  load user %v" is worded the same as other ad-hoc errors in the package \("cannot load user %d: %w" on line 13\)`
+	case 2:
+		return fmt.Errorf("failed to load user %d", id)
+	case 3:
+		return errors.New("store closed") // want `errors.New "store closed" is worded the same as other ad-hoc errors in the package \("store closed" on line 21, "store closed: %w" on line 23\)`
+	case 4:
+		return errors.New("store closed") // want `errors.New "store closed" is worded the same`
+	case 5:
+		return fmt.Errorf("store closed: %w", err) // want `fmt.Errorf "store closed: %w" is worded the same`
+	}
+
+	return fmt.Errorf("something unrelated happened")
+}
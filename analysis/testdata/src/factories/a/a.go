@@ -0,0 +1,39 @@
+package a
+
+import (
+	"errors"
+
+	"github.com/a20r/falta"
+)
+
+var store = falta.Namespace("store")
+
+var (
+	ErrUsed        = falta.Newf("no user with id %d")
+	ErrMatchedOnly = falta.NewError("matched only")
+	errUnused      = falta.Newf("unused %d")
+	ErrScoped      = store.NewError("closed")
+	ErrDuplicate   = falta.NewError("duplicate")
+	ErrNested      = store.Namespace("cache").Newf("miss for %s")
+	ErrDynamic     = falta.NewError(dynamic())
+)
+
+func dynamic() string {
+	return "dynamic"
+}
+
+func Duplicate() error {
+	return ErrDuplicate
+}
+
+func Matches(err error) bool {
+	return errors.Is(err, ErrMatchedOnly)
+}
+
+func Scoped(err error) error {
+	if ErrNested == nil {
+		return ErrDynamic
+	}
+
+	return ErrScoped.Wrap(err)
+}
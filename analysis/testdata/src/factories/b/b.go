@@ -0,0 +1,25 @@
+package b
+
+import (
+	"factories/a"
+
+	"github.com/a20r/falta"
+)
+
+var (
+	ErrDuplicate   = falta.NewError("duplicate")
+	ErrStoreClosed = falta.NewError("store: closed")
+	ErrCacheMiss   = falta.Newf("store: cache: miss for %s")
+
+	loadErrors = falta.Set(a.ErrUsed, ErrDuplicate)
+)
+
+func Load(id int) error {
+	if loadErrors.Contains(nil) {
+		return ErrStoreClosed
+	}
+
+	falta.Match(nil).Case(ErrCacheMiss, func(falta.Falta) {})
+
+	return a.ErrUsed.New(id)
+}
@@ -0,0 +1,18 @@
+package c
+
+import "github.com/a20r/falta"
+
+var disk = falta.Namespace("disk 100%")
+
+var (
+	ErrFull     = disk.Newf("full")
+	ErrDiskFull = falta.Newf("disk 100%%: full")
+)
+
+func Full(scoped bool) error {
+	if scoped {
+		return ErrFull.New()
+	}
+
+	return ErrDiskFull.New()
+}
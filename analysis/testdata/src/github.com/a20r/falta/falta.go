@@ -0,0 +1,54 @@
+// Package falta is a stub of the real package for the analyzer tests, with just the API they look at.
+package falta
+
+import "context"
+
+type Option func()
+
+type Falta struct{ msg string }
+
+func (f Falta) Error() string                              { return f.msg }
+func (f Falta) Wrap(err error) Falta                       { return f }
+func (f Falta) Annotate(annotation string) Falta           { return f }
+func (f Falta) Annotatef(format string, args ...any) Falta { return f }
+func (f Falta) Capture(err *error)                         {}
+func (f Falta) CaptureCtx(ctx context.Context, err *error) {}
+
+type Factory[T any] interface {
+	error
+	New(vs ...T) Falta
+}
+
+type fmtFalta struct{ errFmt string }
+
+func (f fmtFalta) Error() string       { return f.errFmt }
+func (f fmtFalta) New(vs ...any) Falta { return Falta{f.errFmt} }
+
+func NewCtx[T any](ctx context.Context, factory Factory[T], vs ...any) Falta { return factory.New() }
+
+func Capture[T any](err *error, factory Factory[T], vs ...any)                         {}
+func CaptureCtx[T any](ctx context.Context, err *error, factory Factory[T], vs ...any) {}
+
+func Newf(errFmt string, opts ...Option) Factory[any] { return fmtFalta{errFmt} }
+func NewError(msg string, opts ...Option) Falta       { return Falta{msg} }
+
+type Scope struct{ name string }
+
+func Namespace(name string, opts ...Option) Scope               { return Scope{name} }
+func (s Scope) Newf(errFmt string, opts ...Option) Factory[any] { return fmtFalta{errFmt} }
+func (s Scope) Namespace(name string, opts ...Option) Scope     { return Scope{name} }
+func (s Scope) NewError(msg string, opts ...Option) Falta       { return Falta{msg} }
+
+type ErrorSet struct{ members []error }
+
+func Set(members ...error) ErrorSet { return ErrorSet{members} }
+
+func (s ErrorSet) Contains(err error) bool { return false }
+
+type Matcher struct{ err error }
+
+func Match(err error) *Matcher                                { return &Matcher{err} }
+func (m *Matcher) Case(target error, fn func(Falta)) *Matcher { return m }
+func (m *Matcher) Default(fn func(error))                     {}
+
+func CaseData[T any](m *Matcher, factory Factory[T], fn func(T, Falta)) *Matcher { return m }
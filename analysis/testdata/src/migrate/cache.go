@@ -0,0 +1,10 @@
+package migrate
+
+import "fmt"
+
+func evict(key string) error {
+	fmt.Println("evicting", key)
+	return fmt.Errorf("cannot load user %d", len(key))
+}
+
+func errCannotLoadUser() {}
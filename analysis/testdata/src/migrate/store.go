@@ -0,0 +1,53 @@
+// Package migrate is rewritten by the errorf tests.
+package migrate
+
+import (
+	"errors"
+	"fmt"
+	"io"
+)
+
+var errSentinel = errors.New("declared once already")
+
+// load reports the same failure in three places.
+func load(id int, err error) error {
+	switch id {
+	case 0:
+		return fmt.Errorf("cannot load user %d: %w", id, err)
+	case 1:
+		return fmt.Errorf("cannot load user %d", id+1)
+	case 2:
+		return errors.New("store closed")
+	case 3:
+		return fmt.Errorf("store closed: %w", io.EOF)
+	case 4:
+		return fmt.Errorf("%w: while loading", err)
+	}
+
+	return errSentinel
+}
+
+// fetch keeps err an error, so that it can be assigned the error of next.
+func fetch(next func() error) error {
+	err := errors.New("no data")
+
+	if next != nil {
+		err = next()
+	}
+
+	return err
+}
+
+// check formats nothing, so its %% is printed as a single %.
+func check(used int) error {
+	if used == 100 {
+		return fmt.Errorf("disk 100%% full")
+	}
+
+	return nil
+}
+
+// loadRow wraps an error it builds in the same call, so both calls are rewritten.
+func loadRow(id int) error {
+	return fmt.Errorf("cannot load row: %w", fmt.Errorf("no row %d", id))
+}
@@ -0,0 +1,156 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/a20r/falta"
+)
+
+var (
+	ErrNotFound = falta.Newf("no user with id %d")
+	ErrClosed   = falta.NewError("store is closed")
+	ErrOther    = falta.NewError("something else")
+	ErrTimeout  = falta.Newf("timed out loading user %d")
+
+	LoadErrors = falta.Set(ErrNotFound, ErrClosed) // want LoadErrors:`set\(store.ErrNotFound, store.ErrClosed\)`
+	ClosedOnly = falta.Set(ErrClosed)              // want ClosedOnly:`set\(store.ErrClosed\)`
+
+	bad = falta.Set(errors.New("inline")) // want `falta.Set members must be package-level factories or errors` bad:`set\(\)`
+)
+
+//falta:returns LoadErrors
+func Load(id int) (string, error) { // want Load:`returns\(LoadErrors\)`
+	switch id {
+	case 0:
+		return "", nil
+	case 1:
+		return "", ErrClosed
+	case 2:
+		return "", ErrNotFound.New(id).Annotate("while loading")
+	case 3:
+		return "", ErrClosed.Wrap(errors.New("disk"))
+	case 4:
+		return "", (ErrNotFound.New(id))
+	case 5:
+		return "", ErrOther // want `Load returns an error that is not from its set LoadErrors`
+	case 6:
+		return "", fmt.Errorf("wrapped: %w", ErrClosed) // want `Load returns an error that is not from its set LoadErrors`
+	case 7:
+		return "", ErrOther.Wrap(ErrClosed) // want `Load returns an error that is not from its set LoadErrors`
+	}
+
+	return Close()
+}
+
+//falta:returns ClosedOnly
+func Close() (string, error) { // want Close:`returns\(ClosedOnly\)`
+	return "", ErrClosed
+}
+
+//falta:returns ClosedOnly
+func Reload() (string, error) { // want Reload:`returns\(ClosedOnly\)`
+	return Load(1) // want `Reload may return errors outside its set ClosedOnly`
+}
+
+//falta:returns LoadErrors
+func Propagate(id int) (string, error) { // want Propagate:`returns\(LoadErrors\)`
+	v, err := Load(id)
+
+	if err != nil {
+		return "", err
+	}
+
+	if _, err = Close(); err != nil {
+		return "", err
+	}
+
+	if v == "" {
+		err = ErrNotFound.New(id)
+	}
+
+	return v, err
+}
+
+//falta:returns ClosedOnly
+func PropagateLoad(id int) (string, error) { // want PropagateLoad:`returns\(ClosedOnly\)`
+	v, err := Load(id)
+	return v, err // want `PropagateLoad returns an error that is not from its set ClosedOnly`
+}
+
+//falta:returns LoadErrors
+func Forward(err error) (string, error) { // want Forward:`returns\(LoadErrors\)`
+	if err == nil {
+		err = errors.New("anything")
+	}
+
+	return "", err // want `Forward returns an error that is not from its set LoadErrors`
+}
+
+//falta:returns LoadErrors
+func Named(id int) (err error) { // want Named:`returns\(LoadErrors\)`
+	if id == 0 {
+		err = ErrNotFound.New(id)
+	}
+
+	return err
+}
+
+//falta:returns LoadErrors
+func NamedOther(id int) (v string, err error) { // want NamedOther:`returns\(LoadErrors\)`
+	v, err = Load(id)
+
+	if err == nil {
+		err = errors.New("anything")
+	}
+
+	return v, err // want `NamedOther returns an error that is not from its set LoadErrors`
+}
+
+//falta:returns LoadErrors
+func Captured(id int) (_ string, err error) { // want Captured:`returns\(LoadErrors\)`
+	defer ErrNotFound.New(id).Capture(&err)
+
+	return "", errors.New("anything goes")
+}
+
+//falta:returns LoadErrors
+func LoadCtx(ctx context.Context, id int) (string, error) { // want LoadCtx:`returns\(LoadErrors\)`
+	if id == 0 {
+		return "", falta.NewCtx(ctx, ErrNotFound, id).Annotate("while loading")
+	}
+
+	return "", falta.NewCtx(ctx, ErrTimeout, id) // want `LoadCtx returns an error that is not from its set LoadErrors`
+}
+
+//falta:returns LoadErrors
+func CapturedFunc(ctx context.Context, id int) (_ string, err error) { // want CapturedFunc:`returns\(LoadErrors\)`
+	defer falta.CaptureCtx(ctx, &err, ErrNotFound, id)
+
+	return "", errors.New("anything goes")
+}
+
+//falta:returns LoadErrors
+func CapturedOther(id int) (_ string, err error) { // want CapturedOther:`returns\(LoadErrors\)`
+	defer falta.Capture(&err, ErrTimeout, id)
+
+	return "", errors.New("disk") // want `CapturedOther returns an error that is not from its set LoadErrors`
+}
+
+//falta:returns Missing
+func Unknown() error { // want `Missing is not a package-level falta.Set`
+	return nil
+}
+
+//falta:returns LoadErrors
+func NoError() string { // want `NoError declares an error set but does not return an error`
+	return ""
+}
+
+func Handle() {
+	_, err := Load(1)
+
+	falta.Match(err). // want `falta.Match over LoadErrors is not exhaustive: missing store.ErrNotFound; add the cases or a Default`
+				Case(ErrClosed, func(falta.Falta) {})
+}
@@ -0,0 +1,36 @@
+package verbs
+
+import "github.com/a20r/falta"
+
+const (
+	closed  = "store is closed"
+	percent = "disk is 100% full"
+	verb    = "no user with id %d"
+)
+
+var (
+	errClosed  = falta.NewError("store is closed")
+	errConst   = falta.NewError(closed)
+	errEscaped = falta.NewError("disk is 100%% full")
+	errPercent = falta.NewError(percent)
+	errVerb    = falta.NewError("no user with id %d") // want `falta.NewError is passed a string with the verb %d, which panics at runtime; use falta.Newf instead`
+	errNamed   = falta.NewError(verb)                 // want `falta.NewError is passed a string with the verb %d`
+
+	store      = falta.Namespace("store")
+	errScoped  = store.NewError("closed")
+	errScopedV = store.NewError("%v closed") // want `Scope.NewError is passed a string with the verb %v, which panics at runtime; use Scope.Newf instead`
+)
+
+func declare(msg string) falta.Falta {
+	return falta.NewError(msg) // want `falta.NewError is passed a non-constant string, which cannot be checked for verbs and panics if it has one; use falta.Newf instead`
+}
+
+func annotate(f falta.Falta, table string) falta.Falta {
+	f = f.Annotate("while loading")
+	f = f.Annotate("while loading " + closed)
+	f = f.Annotate("while loading %s")       // want `Annotate is passed a string with the verb %s, which panics at runtime; use Annotatef instead`
+	f = f.Annotate("while loading " + table) // want `Annotate is passed a non-constant string, which cannot be checked for verbs and panics if it has one; use Annotatef instead`
+	f = f.Annotatef("while loading %s", table)
+
+	return f.Annotate(table) // want `Annotate is passed a non-constant string`
+}
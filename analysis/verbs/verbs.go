@@ -0,0 +1,123 @@
+// Package verbs defines an analyzer that finds fmt verbs in the strings passed to falta.NewError and Falta.Annotate.
+//
+// Both panic at runtime when their string has a verb, since they would otherwise print it verbatim, and Annotate
+// panics at the call site, possibly in production. The analyzer moves the check to vet time: it reports constant
+// strings with verbs, and non-constant strings, which cannot be checked and are safer passed through Newf or
+// Annotatef. For a non-constant annotation, it suggests Annotatef("%s", s).
+package verbs
+
+import (
+	"fmt"
+	"go/ast"
+	"go/constant"
+	"go/types"
+	"regexp"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/inspect"
+	"golang.org/x/tools/go/ast/astutil"
+	"golang.org/x/tools/go/ast/inspector"
+)
+
+const faltaPath = "github.com/a20r/falta"
+
+// Analyzer reports verbs in the strings passed to falta.NewError, Scope.NewError and Falta.Annotate.
+var Analyzer = &analysis.Analyzer{
+	Name:     "faltaverbs",
+	Doc:      "check that the strings passed to falta.NewError and Falta.Annotate have no fmt verbs",
+	Requires: []*analysis.Analyzer{inspect.Analyzer},
+	Run:      run,
+}
+
+// verbsRegex is the pattern falta itself panics on.
+var verbsRegex = regexp.MustCompile(`\%\w`)
+
+func run(pass *analysis.Pass) (any, error) {
+	insp := pass.ResultOf[inspect.Analyzer].(*inspector.Inspector)
+
+	insp.Preorder([]ast.Node{(*ast.CallExpr)(nil)}, func(n ast.Node) {
+		call := n.(*ast.CallExpr)
+		name, alternative := callee(pass, call)
+
+		if name == "" || len(call.Args) == 0 {
+			return
+		}
+
+		arg := call.Args[0]
+		tv := pass.TypesInfo.Types[arg]
+
+		if tv.Value == nil {
+			diagnostic := analysis.Diagnostic{
+				Pos: arg.Pos(),
+				End: arg.End(),
+				Message: fmt.Sprintf("%s is passed a non-constant string, which cannot be checked for verbs and panics "+
+					"if it has one; use %s instead", name, alternative),
+			}
+
+			if alternative == "Annotatef" {
+				diagnostic.SuggestedFixes = annotatefFix(call, arg)
+			}
+
+			pass.Report(diagnostic)
+			return
+		}
+
+		if tv.Value.Kind() != constant.String {
+			return
+		}
+
+		if verb := verbsRegex.FindString(constant.StringVal(tv.Value)); verb != "" {
+			pass.Reportf(arg.Pos(), "%s is passed a string with the verb %s, which panics at runtime; use %s instead",
+				name, verb, alternative)
+		}
+	})
+
+	return nil, nil
+}
+
+// callee returns the name of the falta function or method called, and the one to use instead, or two empty strings
+// if call is not to NewError or Annotate.
+func callee(pass *analysis.Pass, call *ast.CallExpr) (name, alternative string) {
+	sel, ok := astutil.Unparen(call.Fun).(*ast.SelectorExpr)
+
+	if !ok {
+		return "", ""
+	}
+
+	fn, ok := pass.TypesInfo.Uses[sel.Sel].(*types.Func)
+
+	if !ok || fn.Pkg() == nil || fn.Pkg().Path() != faltaPath {
+		return "", ""
+	}
+
+	recv := fn.Type().(*types.Signature).Recv()
+
+	switch {
+	case fn.Name() == "NewError" && recv == nil:
+		return "falta.NewError", "falta.Newf"
+	case fn.Name() == "NewError" && isNamed(recv.Type(), "Scope"):
+		return "Scope.NewError", "Scope.Newf"
+	case fn.Name() == "Annotate" && isNamed(recv.Type(), "Falta"):
+		return "Annotate", "Annotatef"
+	default:
+		return "", ""
+	}
+}
+
+func isNamed(t types.Type, name string) bool {
+	named, ok := t.(*types.Named)
+	return ok && named.Obj().Name() == name
+}
+
+// annotatefFix rewrites Annotate(s) to Annotatef("%s", s).
+func annotatefFix(call *ast.CallExpr, arg ast.Expr) []analysis.SuggestedFix {
+	sel := astutil.Unparen(call.Fun).(*ast.SelectorExpr)
+
+	return []analysis.SuggestedFix{{
+		Message: "Use Annotatef",
+		TextEdits: []analysis.TextEdit{
+			{Pos: sel.Sel.Pos(), End: sel.Sel.End(), NewText: []byte("Annotatef")},
+			{Pos: arg.Pos(), End: arg.Pos(), NewText: []byte(`"%s", `)},
+		},
+	}}
+}
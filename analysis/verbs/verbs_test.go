@@ -0,0 +1,13 @@
+package verbs_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/a20r/falta/analysis/verbs"
+	"golang.org/x/tools/go/analysis/analysistest"
+)
+
+func TestAnalyzer(t *testing.T) {
+	analysistest.RunWithSuggestedFixes(t, filepath.Join(analysistest.TestData(), "..", "..", "testdata"), verbs.Analyzer, "verbs")
+}
@@ -0,0 +1,18 @@
+package falta
+
+import (
+	"fmt"
+)
+
+// Annotatef adds an annotation formatted from the format string and arguments provided. Unlike Annotate, it accepts
+// fmt verbs, so it is the safe way to put values into an annotation.
+func (f Falta) Annotatef(format string, args ...any) Falta {
+	annotation := fmt.Sprintf(format, f.options().sanitizeArgs(args)...)
+	return report(EventAnnotate, f.withNote(note{kind: noteAnnotation, annotation: annotation}))
+}
+
+// Annotations returns the annotations of every Falta in err's chain, innermost first, in the order they were added to
+// each error.
+func Annotations(err error) []string {
+	return noteAnnotations(chainNotes(err, noteAnnotation))
+}
@@ -0,0 +1,56 @@
+package falta_test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/a20r/falta"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAnnotatef(t *testing.T) {
+	as := assert.New(t)
+	factory := falta.Newf("http: request to %s failed")
+
+	var err falta.Falta
+
+	as.NotPanics(func() {
+		err = factory.New("/v1/users").Annotatef("retry budget exhausted after %d attempts", 3)
+	})
+
+	as.EqualError(err, "http: request to /v1/users failed: retry budget exhausted after 3 attempts")
+	as.ErrorIs(err, factory)
+	as.Equal([]string{"retry budget exhausted after 3 attempts"}, falta.Annotations(err))
+}
+
+func TestAnnotations(t *testing.T) {
+	as := assert.New(t)
+
+	inner := falta.Newf("db: query failed").New().Annotate("connection reset").Annotatef("after %dms", 30)
+	middle := fmt.Errorf("repo: %w", inner)
+	outer := falta.Newf("api: cannot load user %d").New(7).Annotate("giving up").Wrap(middle).Annotate("cache is cold")
+
+	as.Equal([]string{"connection reset", "after 30ms", "giving up", "cache is cold"}, falta.Annotations(outer),
+		"innermost error first, each error's annotations in the order they were added")
+	as.EqualError(outer,
+		"api: cannot load user 7: giving up: repo: db: query failed: connection reset: after 30ms: cache is cold",
+		"annotations render where they were added relative to the cause")
+
+	as.Empty(falta.Annotations(falta.Newf("boom").New()))
+	as.Empty(falta.Annotations(errors.New("plain")))
+	as.Empty(falta.Annotations(nil))
+}
+
+func TestAnnotations_DoNotShareState(t *testing.T) {
+	as := assert.New(t)
+	base := falta.NewError("boom").Annotate("a")
+
+	left := base.Annotate("b")
+	right := base.Annotate("c")
+
+	as.Equal([]string{"a"}, falta.Annotations(base))
+	as.Equal([]string{"a", "b"}, falta.Annotations(left))
+	as.Equal([]string{"a", "c"}, falta.Annotations(right))
+	as.EqualError(right, "boom: a: c")
+}
@@ -0,0 +1,85 @@
+package falta
+
+import (
+	"fmt"
+	"log/slog"
+)
+
+// Attr is a key-value attribute attached to a Falta with With.
+type Attr struct {
+	Key   string
+	Value any
+}
+
+// String returns the attribute as key=value.
+func (a Attr) String() string {
+	return fmt.Sprintf("%s=%v", a.Key, a.Value)
+}
+
+// AttrRendering controls whether the attributes attached to a Falta show up in its message.
+type AttrRendering int
+
+const (
+	// AttrsHidden keeps attributes out of Error(). They are only reachable through Attrs and structured sinks such
+	// as slog. This is the default.
+	AttrsHidden AttrRendering = iota
+
+	// AttrsInline appends each attribute to the message as key=value.
+	AttrsInline
+)
+
+// WithAttrRendering sets how the errors built by a factory render the attributes attached to them with With.
+func WithAttrRendering(r AttrRendering) Option {
+	return func(o *options) {
+		o.attrRendering = r
+	}
+}
+
+// With attaches a typed attribute to the error. Attributes do not change the error's identity, so the result still
+// matches its factory with errors.Is.
+func (f Falta) With(key string, value any) Falta {
+	return f.withNote(note{kind: noteAttr, attr: Attr{Key: key, Value: value}})
+}
+
+// LogValue implements slog.LogValuer so structured loggers receive the message, and the ID, severity, annotations
+// and attributes of the whole chain, as separate fields.
+func (f Falta) LogValue() slog.Value {
+	attrs := []slog.Attr{slog.String("msg", f.Error())}
+
+	if id := ID(f); id != "" {
+		attrs = append(attrs, slog.String("id", id))
+	}
+
+	if severity := Severity(f); severity != 0 {
+		attrs = append(attrs, slog.String("severity", severity.String()))
+	}
+
+	if annotations := Annotations(f); len(annotations) > 0 {
+		attrs = append(attrs, slog.Any("annotations", annotations))
+	}
+
+	for _, attr := range Attrs(f) {
+		attrs = append(attrs, slog.Any(attr.Key, attr.Value))
+	}
+
+	return slog.GroupValue(attrs...)
+}
+
+// Attrs returns the attributes attached to every Falta in err's chain, innermost first. When a key is attached more
+// than once, the outermost value wins and keeps the position of the innermost one.
+func Attrs(err error) []Attr {
+	var attrs []Attr
+	index := make(map[string]int)
+
+	for _, n := range chainNotes(err, noteAttr) {
+		if i, ok := index[n.attr.Key]; ok {
+			attrs[i] = n.attr
+			continue
+		}
+
+		index[n.attr.Key] = len(attrs)
+		attrs = append(attrs, n.attr)
+	}
+
+	return attrs
+}
@@ -0,0 +1,90 @@
+package falta_test
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"log/slog"
+	"testing"
+
+	"github.com/a20r/falta"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWith(t *testing.T) {
+	as := assert.New(t)
+	factory := falta.Newf("user store: no user with id %d")
+
+	err := factory.New(42).With("tenant", "acme").With("attempt", 3)
+
+	as.EqualError(err, "user store: no user with id 42", "attributes are hidden from the message by default")
+	as.ErrorIs(err, factory, "attributes should not change identity")
+	as.Equal([]falta.Attr{{Key: "tenant", Value: "acme"}, {Key: "attempt", Value: 3}}, falta.Attrs(err))
+}
+
+func TestWith_Inline(t *testing.T) {
+	as := assert.New(t)
+	factory := falta.Newf("user store: no user with id %d", falta.WithAttrRendering(falta.AttrsInline))
+
+	err := factory.New(42).With("tenant", "acme").Annotate("during sync").With("attempt", 3)
+
+	as.EqualError(err, "user store: no user with id 42 tenant=acme: during sync attempt=3")
+	as.ErrorIs(err, factory)
+
+	sentinel := falta.NewError("queue: already closed", falta.WithAttrRendering(falta.AttrsInline))
+	as.EqualError(sentinel.With("queue", "jobs"), "queue: already closed queue=jobs")
+
+	extended := factory.Extend(falta.Newf("in region %s"))
+	as.EqualError(extended.New(1, "eu").With("tenant", "acme"), "user store: no user with id 1 in region eu tenant=acme",
+		"extended factories inherit the options of the factory they extend")
+}
+
+func TestWith_DoesNotShareState(t *testing.T) {
+	as := assert.New(t)
+	base := falta.Newf("boom: %s").New("x").With("a", 1)
+
+	left := base.With("b", 2)
+	right := base.With("c", 3)
+
+	as.Equal([]falta.Attr{{Key: "a", Value: 1}}, falta.Attrs(base))
+	as.Equal([]falta.Attr{{Key: "a", Value: 1}, {Key: "b", Value: 2}}, falta.Attrs(left))
+	as.Equal([]falta.Attr{{Key: "a", Value: 1}, {Key: "c", Value: 3}}, falta.Attrs(right))
+}
+
+func TestAttrs_Chain(t *testing.T) {
+	as := assert.New(t)
+
+	inner := falta.Newf("db: query failed").New().With("table", "users").With("attempt", 1)
+	middle := fmt.Errorf("repo: %w", inner)
+	outer := falta.Newf("api: cannot load user %d").New(7).With("attempt", 3).With("route", "/users").Wrap(middle)
+
+	as.Equal([]falta.Attr{
+		{Key: "table", Value: "users"},
+		{Key: "attempt", Value: 3},
+		{Key: "route", Value: "/users"},
+	}, falta.Attrs(outer), "innermost first, with the outermost value winning for a repeated key")
+
+	as.Empty(falta.Attrs(errors.New("plain")))
+	as.Empty(falta.Attrs(nil))
+}
+
+func TestFalta_LogValue(t *testing.T) {
+	buf := new(bytes.Buffer)
+	logger := slog.New(slog.NewTextHandler(buf, &slog.HandlerOptions{
+		ReplaceAttr: func(_ []string, a slog.Attr) slog.Attr {
+			if a.Key == slog.TimeKey {
+				return slog.Attr{}
+			}
+
+			return a
+		},
+	}))
+
+	err := falta.Newf("user store: no user with id %d").New(42).With("tenant", "acme").Annotate("cache miss")
+	logger.Error("lookup failed", "err", err)
+
+	assert.Equal(t,
+		`level=ERROR msg="lookup failed" err.msg="user store: no user with id 42: cache miss" `+
+			`err.annotations="[cache miss]" err.tenant=acme`+"\n",
+		buf.String())
+}
@@ -0,0 +1,138 @@
+package falta
+
+import (
+	"reflect"
+	"runtime"
+	"strings"
+)
+
+// Frame describes a single layer of an error chain, as returned by Chain.
+type Frame struct {
+	// Err is the error at this layer, including everything it wraps.
+	Err error
+
+	// Depth is how many layers separate this one from the error passed to Chain, which has a depth of 0.
+	Depth int
+
+	// Falta reports whether the layer is a Falta. The fields below are only set when it is.
+	Falta bool
+
+	// Message is the layer's own text, without the text of the errors it wraps. For a Falta it is the rendered
+	// declaration, without annotations or attributes.
+	Message string
+
+	// Format is the declaration string of the factory that built the layer.
+	Format string
+
+	// Code is the code the layer's factory was declared with, if any.
+	Code string
+
+	// Payload is what the layer was built from: the arguments of a Newf factory or the value passed to a New or NewM
+	// factory. It is nil for errors built without arguments.
+	Payload any
+
+	// Annotations are the layer's annotations in the order they were added.
+	Annotations []string
+
+	// Attrs are the layer's attributes in the order they were added.
+	Attrs []Attr
+
+	// Severity is the layer's severity, as declared by its factory or overridden with Falta.WithSeverity.
+	Severity Level
+
+	// Caller is where the layer was built, if its factory was declared with WithStack.
+	Caller runtime.Frame
+
+	// ID is the layer's instance ID, if its factory was declared with WithID.
+	ID string
+}
+
+// Chain flattens err and everything it wraps into a list of frames, outermost first. Errors that wrap several others,
+// such as those made by errors.Join, are walked depth first, so a frame's causes follow it with a greater Depth. An
+// error that wraps one of its own ancestors is only listed the first time, which keeps cyclic chains finite.
+func Chain(err error) []Frame {
+	var frames []Frame
+	appendFrames(&frames, err, 0, nil)
+	return frames
+}
+
+func appendFrames(frames *[]Frame, err error, depth int, ancestors []error) {
+	if err == nil || isAncestor(err, ancestors) {
+		return
+	}
+
+	*frames = append(*frames, newFrame(err, depth))
+	ancestors = append(ancestors, err)
+
+	for _, cause := range causes(err) {
+		appendFrames(frames, cause, depth+1, ancestors)
+	}
+}
+
+func newFrame(err error, depth int) Frame {
+	f, ok := asLayer(err)
+
+	if !ok {
+		return Frame{Err: err, Depth: depth, Message: ownMessage(err)}
+	}
+
+	return Frame{
+		Err:         err,
+		Depth:       depth,
+		Falta:       true,
+		Message:     f.msg,
+		Format:      f.errFmt,
+		Code:        f.options().code,
+		Payload:     f.payload(),
+		Annotations: noteAnnotations(f.notes(noteAnnotation)),
+		Attrs:       noteAttrs(f.notes(noteAttr)),
+		Severity:    f.severity(),
+		Caller:      f.caller(),
+		ID:          f.id(),
+	}
+}
+
+// ownMessage strips the text of the errors err wraps from the end of its message, for errors that follow the usual
+// "context: cause" convention.
+func ownMessage(err error) string {
+	msg := err.Error()
+	wrapped := causes(err)
+
+	if len(wrapped) != 1 {
+		var texts []string
+
+		for _, cause := range wrapped {
+			if cause != nil {
+				texts = append(texts, cause.Error())
+			}
+		}
+
+		if msg == strings.Join(texts, "\n") {
+			return ""
+		}
+
+		return msg
+	}
+
+	if trimmed, ok := strings.CutSuffix(msg, ": "+wrapped[0].Error()); ok {
+		return trimmed
+	}
+
+	return strings.TrimSuffix(msg, wrapped[0].Error())
+}
+
+// isAncestor reports whether err is one of the errors on the path that led to it. A chain can only loop back on
+// itself through a pointer, so only pointer errors are compared.
+func isAncestor(err error, ancestors []error) bool {
+	if reflect.TypeOf(err).Kind() != reflect.Pointer {
+		return false
+	}
+
+	for _, ancestor := range ancestors {
+		if ancestor == err { //nolint:errorlint // identity, not equivalence, is what makes a cycle
+			return true
+		}
+	}
+
+	return false
+}
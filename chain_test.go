@@ -0,0 +1,102 @@
+package falta_test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/a20r/falta"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestChain(t *testing.T) {
+	as := assert.New(t)
+
+	cause := errors.New("connection reset")
+	inner := falta.Newf("db: query on %s failed").New("users").Annotate("read replica").With("attempt", 2).Wrap(cause)
+	outer := falta.NewError("api: cannot load user").Wrap(fmt.Errorf("repo: %w", inner))
+
+	frames := falta.Chain(outer)
+
+	as.Len(frames, 4)
+
+	as.Equal(falta.Frame{
+		Err:     outer,
+		Depth:   0,
+		Falta:   true,
+		Message: "api: cannot load user",
+		Format:  "api: cannot load user",
+	}, frames[0])
+
+	as.Equal(falta.Frame{Err: errors.Unwrap(outer), Depth: 1, Message: "repo"}, frames[1])
+
+	as.Equal(falta.Frame{
+		Err:         inner,
+		Depth:       2,
+		Falta:       true,
+		Message:     "db: query on users failed",
+		Format:      "db: query on %s failed",
+		Payload:     []any{"users"},
+		Annotations: []string{"read replica"},
+		Attrs:       []falta.Attr{{Key: "attempt", Value: 2}},
+	}, frames[2])
+
+	as.Equal(falta.Frame{Err: cause, Depth: 3, Message: "connection reset"}, frames[3])
+}
+
+func TestChain_Join(t *testing.T) {
+	as := assert.New(t)
+
+	errDB := falta.NewError("db: close failed")
+	errQueue := errors.New("queue: close failed")
+	err := falta.NewError("shutdown failed").Wrap(errors.Join(errDB, fmt.Errorf("workers: %w", errQueue)))
+
+	var summary []string
+
+	for _, frame := range falta.Chain(err) {
+		summary = append(summary, fmt.Sprintf("%d %t %q", frame.Depth, frame.Falta, frame.Message))
+	}
+
+	as.Equal([]string{
+		`0 true "shutdown failed"`,
+		`1 false ""`,
+		`2 true "db: close failed"`,
+		`2 false "workers"`,
+		`3 false "queue: close failed"`,
+	}, summary)
+}
+
+// loopErr is an error whose chain leads back to itself.
+type loopErr struct {
+	next error
+}
+
+func (e *loopErr) Error() string { return "loop" }
+func (e *loopErr) Unwrap() error { return e.next }
+
+func TestChain_Cycle(t *testing.T) {
+	as := assert.New(t)
+
+	first := &loopErr{}
+	second := &loopErr{next: falta.NewError("in between")}
+	first.next = second
+	second.next = first
+
+	frames := falta.Chain(first)
+
+	as.Len(frames, 2, "the walk should stop when the chain loops back to an ancestor")
+	as.Same(first, frames[0].Err)
+	as.Same(second, frames[1].Err)
+}
+
+func TestChain_Repeated(t *testing.T) {
+	shared := errors.New("shared")
+
+	frames := falta.Chain(errors.Join(shared, fmt.Errorf("again: %w", shared)))
+
+	assert.Len(t, frames, 4, "an error reached along two branches is not a cycle and is listed on both")
+}
+
+func TestChain_Nil(t *testing.T) {
+	assert.Empty(t, falta.Chain(nil))
+}
@@ -0,0 +1,22 @@
+package falta
+
+import "errors"
+
+// WithCode gives a factory a short, stable code, such as "USER_NOT_FOUND", that identifies its errors to machines and
+// people without quoting the message.
+func WithCode(code string) Option {
+	return func(o *options) {
+		o.code = code
+	}
+}
+
+// Code returns the code of the outermost Falta in err's chain that has one, or "" if none does.
+func Code(err error) string {
+	for ; err != nil; err = errors.Unwrap(err) {
+		if f, ok := asLayer(err); ok && f.options().code != "" {
+			return f.options().code
+		}
+	}
+
+	return ""
+}
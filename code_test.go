@@ -0,0 +1,29 @@
+package falta_test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/a20r/falta"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCode(t *testing.T) {
+	as := assert.New(t)
+
+	errNotFound := falta.Newf("user store: no user with id %d", falta.WithCode("USER_NOT_FOUND"))
+	errLoad := falta.NewError("api: cannot load user", falta.WithCode("USER_LOAD"))
+	errUncoded := falta.NewM("repo: {{.op}} failed")
+
+	as.Equal("USER_NOT_FOUND", falta.Code(errNotFound.New(42)))
+	as.Equal("USER_LOAD", falta.Code(errLoad.Wrap(errNotFound.New(42))), "the outermost code wins")
+	as.Equal("USER_NOT_FOUND", falta.Code(fmt.Errorf("handler: %w", errUncoded.New(falta.M{"op": "get"}).Wrap(errNotFound.New(42)))),
+		"layers without a code are skipped")
+	as.Empty(falta.Code(errUncoded.New(falta.M{"op": "get"})))
+	as.Empty(falta.Code(errors.New("plain")))
+	as.Empty(falta.Code(nil))
+
+	as.Equal("USER_NOT_FOUND", falta.Code(errNotFound.Extend(falta.Newf("in region %s")).New(42, "eu")),
+		"extended factories keep the code of the factory they extend")
+}
@@ -0,0 +1,140 @@
+package falta
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"slices"
+	"sync"
+)
+
+// ContextExtractor reads a value from a context, reporting false if the context does not carry one.
+type ContextExtractor func(ctx context.Context) (any, bool)
+
+// contextAttrs holds the extractors registered with RegisterContextAttr, in the order they were registered.
+var contextAttrs = &contextAttrRegistry{}
+
+type contextAttr struct {
+	key     string
+	extract ContextExtractor
+}
+
+type contextAttrRegistry struct {
+	mu    sync.RWMutex
+	attrs []contextAttr
+}
+
+func (r *contextAttrRegistry) add(key string, extract ContextExtractor) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i, attr := range r.attrs {
+		if attr.key == key {
+			r.attrs[i].extract = extract
+			return
+		}
+	}
+
+	r.attrs = append(r.attrs, contextAttr{key: key, extract: extract})
+}
+
+func (r *contextAttrRegistry) list() []contextAttr {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return slices.Clone(r.attrs)
+}
+
+// RegisterContextAttr registers an extractor that WithContext, NewCtx and CaptureCtx use to copy a request-scoped
+// value, such as a request or tenant ID, from a context into the error as the attribute named key. Registering a key
+// again replaces its extractor. It is meant to be called from init, next to the code that puts the value in the
+// context:
+//
+//	func init() {
+//		falta.RegisterContextAttr("request_id", func(ctx context.Context) (any, bool) {
+//			id, ok := ctx.Value(requestIDKey{}).(string)
+//			return id, ok
+//		})
+//	}
+func RegisterContextAttr(key string, extract ContextExtractor) {
+	contextAttrs.add(key, extract)
+}
+
+// WithContext attaches the values the registered extractors find in ctx as attributes, in the order the extractors
+// were registered. Like With, it does not change the error's identity.
+func (f Falta) WithContext(ctx context.Context) Falta {
+	if ctx == nil {
+		return f
+	}
+
+	for _, attr := range contextAttrs.list() {
+		if value, ok := attr.extract(ctx); ok {
+			f = f.With(attr.key, value)
+		}
+	}
+
+	return f
+}
+
+// CaptureCtx is Capture for functions that have a context: the error it wraps also gets the attributes registered
+// with RegisterContextAttr taken from ctx, and is recorded on the span active in ctx if a SpanRecorder is set.
+//
+// If f was built by NewCtx, it was recorded then, and is not recorded again.
+func (f Falta) CaptureCtx(ctx context.Context, err *error) {
+	if *err == nil {
+		return
+	}
+
+	wrapped := f.WithContext(ctx).wrap(*err)
+
+	if !f.recorded() {
+		wrapped = recordSpan(ctx, wrapped)
+	}
+
+	*err = report(EventCapture, wrapped)
+}
+
+// CaptureCtx is Falta.CaptureCtx for an error that factory builds only if there is one to wrap, as the Capture
+// function is for Falta.Capture. A function that returns nil neither builds nor records anything.
+func CaptureCtx[T any](ctx context.Context, err *error, factory Factory[T], vs ...any) {
+	if *err != nil {
+		*err = report(EventCapture, recordSpan(ctx, receiver(factory, vs).withCaller().WithContext(ctx).wrap(*err)))
+	}
+}
+
+// NewCtx constructs a new error with factory like its New method, with the attributes registered with
+// RegisterContextAttr taken from ctx, and records it on the span active in ctx if a SpanRecorder is set. It is a
+// function rather than a method of Factory so that implementations of Factory outside falta keep compiling.
+//
+// vs are taken as any so that the arguments of a Newf factory need no conversion; they must be of the factory's type.
+//
+// The error is recorded as it is built, so it is meant to be returned. To wrap the errors a function returns, defer
+// the CaptureCtx function instead, which records only when there is an error.
+func NewCtx[T any](ctx context.Context, factory Factory[T], vs ...any) Falta {
+	ts := payloads[T](vs)
+
+	if b, ok := factory.(interface{ build(vs []T) Falta }); ok {
+		return report(EventNew, recordSpan(ctx, b.build(ts).WithContext(ctx).withID().withCaller()))
+	}
+
+	return recordSpan(ctx, factory.New(ts...).WithContext(ctx))
+}
+
+// payloads converts the arguments of a function such as NewCtx to the payload type of its factory. It panics if one is
+// of another type, as the factory's New would fail to compile.
+func payloads[T any](vs []any) []T {
+	ts := make([]T, len(vs))
+
+	for i, v := range vs {
+		t, ok := v.(T)
+
+		if !ok && v != nil {
+			panic(fmt.Errorf("falta: cannot build an error from a %T: the factory takes %s", v,
+				reflect.TypeOf((*T)(nil)).Elem()))
+		}
+
+		ts[i] = t
+	}
+
+	return ts
+}
@@ -0,0 +1,100 @@
+package falta_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/a20r/falta"
+	"github.com/stretchr/testify/assert"
+)
+
+type requestIDKey struct{}
+
+type tenantKey struct{}
+
+func init() {
+	falta.RegisterContextAttr("request_id", func(ctx context.Context) (any, bool) {
+		id, ok := ctx.Value(requestIDKey{}).(string)
+		return id, ok
+	})
+
+	falta.RegisterContextAttr("tenant", func(ctx context.Context) (any, bool) {
+		tenant, ok := ctx.Value(tenantKey{}).(string)
+		return tenant, ok
+	})
+}
+
+func requestContext() context.Context {
+	ctx := context.WithValue(context.Background(), requestIDKey{}, "req-1")
+	return context.WithValue(ctx, tenantKey{}, "acme")
+}
+
+func TestWithContext(t *testing.T) {
+	as := assert.New(t)
+	errLoad := falta.NewError("api: cannot load user")
+
+	err := errLoad.WithContext(requestContext())
+	as.Equal([]falta.Attr{{Key: "request_id", Value: "req-1"}, {Key: "tenant", Value: "acme"}}, falta.Attrs(err),
+		"attributes follow the order the extractors were registered in")
+	as.ErrorIs(err, errLoad)
+	as.EqualError(err, "api: cannot load user")
+
+	partial := context.WithValue(context.Background(), tenantKey{}, "acme")
+	as.Equal([]falta.Attr{{Key: "tenant", Value: "acme"}}, falta.Attrs(errLoad.WithContext(partial)),
+		"values the context does not carry are skipped")
+	as.Empty(falta.Attrs(errLoad.WithContext(context.Background())))
+	as.Empty(falta.Attrs(errLoad.WithContext(nil)), "a nil context should not panic")
+}
+
+func TestNewCtx(t *testing.T) {
+	type lookup struct {
+		ID int
+	}
+
+	as := assert.New(t)
+	ctx := requestContext()
+
+	byFmt := falta.Newf("user store: no user with id %d", falta.WithAttrRendering(falta.AttrsInline))
+	as.EqualError(falta.NewCtx(ctx, byFmt, 42), "user store: no user with id 42 request_id=req-1 tenant=acme")
+	as.ErrorIs(falta.NewCtx(ctx, byFmt, 42), byFmt)
+
+	byTmpl := falta.New[lookup]("user store: no user with id {{.ID}}")
+	err := falta.NewCtx(ctx, byTmpl, lookup{ID: 42})
+	as.EqualError(err, "user store: no user with id 42")
+	as.Equal([]falta.Attr{{Key: "request_id", Value: "req-1"}, {Key: "tenant", Value: "acme"}}, falta.Chain(err)[0].Attrs)
+	as.Contains(fmt.Sprintf("%+v", err), "attributes: [request_id=req-1 tenant=acme]")
+	as.Equal("req-1", err.LogValue().Group()[1].Value.Any())
+
+	as.Empty(falta.Attrs(falta.NewCtx(context.Background(), byTmpl, lookup{ID: 42})))
+
+	id := 42
+	as.EqualError(falta.NewCtx(ctx, falta.Newf("user store: no user with id %d"), id), "user store: no user with id 42",
+		"typed arguments need no conversion to any")
+	as.Panics(func() { falta.NewCtx(ctx, byTmpl, id) }, "arguments must be of the factory's type")
+}
+
+func TestCaptureCtx(t *testing.T) {
+	as := assert.New(t)
+	errLoad := falta.NewError("api: cannot load user")
+	errDB := errors.New("db: connection lost")
+
+	load := func(ctx context.Context, fail bool) (err error) {
+		defer errLoad.CaptureCtx(ctx, &err)
+
+		if fail {
+			return errDB
+		}
+
+		return nil
+	}
+
+	err := load(requestContext(), true)
+	as.ErrorIs(err, errLoad)
+	as.ErrorIs(err, errDB)
+	as.EqualError(err, "api: cannot load user: db: connection lost")
+	as.Equal([]falta.Attr{{Key: "request_id", Value: "req-1"}, {Key: "tenant", Value: "acme"}}, falta.Attrs(err))
+
+	as.NoError(load(requestContext(), false))
+}
@@ -107,6 +107,36 @@ func ExampleFalta_Annotate() {
 	// true
 }
 
+// Annotatef formats values into an annotation, and Annotations reads them
+// back as a list.
+func ExampleFalta_Annotatef() {
+	errRequestFailed := falta.Newf("http: request to %s failed")
+
+	err := errRequestFailed.New("/v1/users").Annotatef("retry budget exhausted after %d attempts", 3)
+
+	fmt.Println(err)
+	fmt.Println(falta.Annotations(err))
+
+	// Output:
+	// http: request to /v1/users failed: retry budget exhausted after 3 attempts
+	// [retry budget exhausted after 3 attempts]
+}
+
+// With attaches typed attributes for structured sinks without touching the
+// message or the error's identity.
+func ExampleFalta_With() {
+	errRequestFailed := falta.Newf("http: request to %s failed")
+
+	err := errRequestFailed.New("/v1/users").With("status", 503).With("attempt", 3)
+
+	fmt.Println(err)
+	fmt.Println(falta.Attrs(err))
+
+	// Output:
+	// http: request to /v1/users failed
+	// [status=503 attempt=3]
+}
+
 // Capture wraps every error a function returns from a single deferred line, so
 // you do not have to repeat the same wrapping at every return site.
 func ExampleFalta_Capture() {
@@ -128,6 +158,25 @@ func ExampleFalta_Capture() {
 	// true
 }
 
+// The Capture function builds its error only when there is one to wrap, so a
+// function that succeeds builds nothing.
+func ExampleCapture() {
+	errLoadFailed := falta.Newf("store: cannot load record %d")
+
+	load := func(id int) (record string, err error) {
+		defer falta.Capture(&err, errLoadFailed, id)
+
+		return "", errors.New("connection refused")
+	}
+
+	_, err := load(7)
+
+	fmt.Println(err)
+
+	// Output:
+	// store: cannot load record 7: connection refused
+}
+
 // Extend composes a general factory with extra detail, keeping one message
 // prefix in one place.
 func ExampleExtendableFactory() {
@@ -3,9 +3,12 @@ package falta
 import (
 	"errors"
 	"fmt"
+	"reflect"
 	"regexp"
+	"slices"
 	"strings"
 	"text/template"
+	"time"
 )
 
 // Factory is an error factory.
@@ -23,37 +26,79 @@ type ExtendableFactory[T any] interface {
 // Falta is an error returned by the Factory
 type Falta struct {
 	errFmt     string
+	msg        string
 	wrappedErr error
-	error
+	opts       *options
+	details    *details
 }
 
 // NewError returns a new Falta error type with the provided error string.
 //
 // NOTE (a20r, 2024-02-25): It panics if the provided message contains any fmt verbs.
-func NewError(msg string) Falta {
+func NewError(msg string, opts ...Option) Falta {
 	panicIfStringHasVerbs(msg)
 
+	o := newOptions(opts)
+	f := newError(msg, o).withCaller()
+	register(o, msg, f)
+	return f
+}
+
+func newError(msg string, opts *options) Falta {
+	opts.render = opts.renderFmt
+	panicIfRedacting(opts)
+
 	return Falta{
 		errFmt: msg,
-		error:  errors.New(msg),
+		msg:    msg,
+		opts:   opts,
+	}
+}
+
+// Error returns the message followed by the annotations, attributes and causes in the order they were added.
+func (f Falta) Error() string {
+	if f.details == nil {
+		return f.options().truncateMessage(f.msg)
+	}
+
+	builder := new(strings.Builder)
+	builder.WriteString(f.msg)
+
+	for _, n := range f.details.notes {
+		switch n.kind {
+		case noteAnnotation:
+			builder.WriteString(": " + n.annotation)
+		case noteAttr:
+			if f.options().attrRendering == AttrsInline {
+				builder.WriteString(" " + f.options().sanitizeString(n.attr.String()))
+			}
+		case noteCause:
+			fmt.Fprintf(builder, ": %v", n.cause)
+		}
 	}
+
+	return f.options().truncateMessage(builder.String())
 }
 
 // Wrap wraps the error provided with the Falta instance.
 func (f Falta) Wrap(err error) Falta {
-	f.error = fmt.Errorf("%s: %w", f.error.Error(), err)
+	return report(EventWrap, f.wrap(err))
+}
+
+func (f Falta) wrap(err error) Falta {
+	f = f.withNote(note{kind: noteCause, cause: err})
 	f.wrappedErr = err
 	return f
 }
 
 // Annotate adds an annotation to the error to provide more context to why it's happening
 //
-// NOTE (a20r, 2024-02-25): It panics if the provided annotation contains any fmt verbs.
+// NOTE (a20r, 2024-02-25): It panics if the provided annotation contains any fmt verbs. Use Annotatef to format
+// values into an annotation.
 func (f Falta) Annotate(annotation string) Falta {
 	panicIfStringHasVerbs(annotation)
 
-	f.error = fmt.Errorf("%s: %s", f.error.Error(), annotation)
-	return f
+	return report(EventAnnotate, f.withNote(note{kind: noteAnnotation, annotation: annotation}))
 }
 
 // Unwrap returns the wrapped error if there is one.
@@ -61,6 +106,104 @@ func (f Falta) Unwrap() error {
 	return f.wrappedErr
 }
 
+func (f Falta) options() *options {
+	if f.opts == nil {
+		return defaultOptions
+	}
+
+	return f.opts
+}
+
+func (f Falta) withNote(n note) Falta {
+	f.details = f.details.clone()
+	f.details.notes = append(f.details.notes, n)
+	return f.withID()
+}
+
+// notes returns the notes of the given kind in the order they were added.
+func (f Falta) notes(kind noteKind) []note {
+	if f.details == nil {
+		return nil
+	}
+
+	var notes []note
+
+	for _, n := range f.details.notes {
+		if n.kind == kind {
+			notes = append(notes, n)
+		}
+	}
+
+	return notes
+}
+
+// chainNotes returns the notes of the given kind from every Falta in err's chain. Notes from the innermost Falta come
+// first, and each Falta's notes are in the order they were added.
+func chainNotes(err error, kind noteKind) []note {
+	var layers [][]note
+
+	for ; err != nil; err = errors.Unwrap(err) {
+		if f, ok := asLayer(err); ok {
+			layers = append(layers, f.notes(kind))
+		}
+	}
+
+	var notes []note
+
+	for i := len(layers) - 1; i >= 0; i-- {
+		notes = append(notes, layers[i]...)
+	}
+
+	return notes
+}
+
+// details holds the parts of a Falta that are not comparable. It sits behind a pointer so that Falta itself stays
+// comparable, and it is cloned before every change so that errors derived from the same Falta never share state.
+type details struct {
+	payload    any
+	raw        any // the payload before redaction, if any of it was redacted
+	notes      []note
+	retryAfter time.Duration
+	severity   Level
+	caller     uintptr // the program counter of the call that built the error, if its factory records it
+	id         string
+	built      bool // whether a factory's New built the error, so it was already reported as EventNew
+	recorded   bool // whether the error was recorded with the span recorder
+}
+
+func (d *details) clone() *details {
+	if d == nil {
+		return new(details)
+	}
+
+	c := *d
+	c.notes = slices.Clip(c.notes)
+	return &c
+}
+
+type noteKind int
+
+const (
+	noteAnnotation noteKind = iota
+	noteAttr
+	noteCause
+)
+
+// note is a piece of context added to a Falta after it was built. Notes are kept in the order they were added so
+// that Error renders each one where it was attached.
+type note struct {
+	kind       noteKind
+	annotation string
+	attr       Attr
+	cause      error
+}
+
+// asLayer reports whether err itself, not anything in its chain, is a Falta.
+func asLayer(err error) (Falta, bool) {
+	f, ok := err.(Falta) //nolint:errorlint // deliberately inspects a single layer of the chain
+	return f, ok
+}
+
 // Is returns true if the error provided is a Falta instance created by the same factory.
 func (f Falta) Is(err error) bool {
 	if f.wrappedErr != nil && errors.Is(err, f.wrappedErr) {
@@ -80,12 +223,45 @@ func (f Falta) Is(err error) bool {
 // value for the error so that the error Capture wraps is the one returned from the function.
 func (f Falta) Capture(err *error) {
 	if *err != nil {
-		*err = f.Wrap(*err)
+		*err = report(EventCapture, f.wrap(*err))
 	}
 }
 
+// Capture is Falta.Capture for an error that factory builds only if there is one to wrap. Deferred as
+//
+//	defer falta.Capture(&err, ErrLoadFailed, id)
+//
+// it wraps what defer ErrLoadFailed.New(id).Capture(&err) would, but a function that returns nil builds nothing, so
+// hooks and the metrics they feed only hear of the error when the capture fires. Like NewCtx, it takes vs as any.
+func Capture[T any](err *error, factory Factory[T], vs ...any) {
+	if *err != nil {
+		*err = report(EventCapture, receiver(factory, vs).withCaller().wrap(*err))
+	}
+}
+
+// receiver builds the error that the Capture and CaptureCtx functions wrap with. The capture is reported rather than
+// the error, so it is not marked as built by New. A factory from outside falta builds it with its New.
+func receiver[T any](factory Factory[T], vs []any) Falta {
+	ts := payloads[T](vs)
+	b, ok := factory.(interface{ build(vs []T) Falta })
+
+	if !ok {
+		return factory.New(ts...)
+	}
+
+	f := b.build(ts).withID()
+	f.details.built = false
+	return f
+}
+
 var verbsRegex = regexp.MustCompile(`\%\w`)
 
+func panicIfRedacting(opts *options) {
+	if len(opts.redact) > 0 {
+		panic(fmt.Errorf("falta: only template factories can redact fields"))
+	}
+}
+
 func panicIfStringHasVerbs(msg string) {
 	if verbsRegex.MatchString(msg) {
 		panic(fmt.Errorf(`falta: string "%s" has verbs`, msg))
@@ -94,8 +270,10 @@ func panicIfStringHasVerbs(msg string) {
 
 // New creates a new Falta instance that construct errors by executing the provided template string on a struct
 // of the type provided.
-func New[T any](errFmt string) Factory[T] {
-	return newTmplFalta[T](errFmt)
+func New[T any](errFmt string, opts ...Option) Factory[T] {
+	f := newTmplFalta[T](errFmt, newOptions(opts))
+	register(f.opts, errFmt, f)
+	return f
 }
 
 // M is a convenience type for using Falta instances with maps.
@@ -103,41 +281,102 @@ type M map[string]any
 
 // NewM returns a new ExtendableFactory instance using a template that expects a falta.M (i.e., map[string]any).
 // This is a convenience function for calling falta.New[falta.M](...)
-func NewM(errFmt string) ExtendableFactory[M] {
-	return newTmplFalta[M](errFmt)
+func NewM(errFmt string, opts ...Option) ExtendableFactory[M] {
+	f := newTmplFalta[M](errFmt, newOptions(opts))
+	register(f.opts, errFmt, f)
+	return f
 }
 
 // Newf creates a new Falta instance that will construct errors using the printf format string provided.
-func Newf(errFmt string) ExtendableFactory[any] {
-	return newFmtFactory(errFmt)
+func Newf(errFmt string, opts ...Option) ExtendableFactory[any] {
+	f := newFmtFactory(errFmt, newOptions(opts))
+	register(f.opts, errFmt, f)
+	return f
 }
 
 type tmplFalta[T any] struct {
 	errFmt string
 	tmpl   *template.Template
+	opts   *options
 }
 
-func newTmplFalta[T any](errFmt string) tmplFalta[T] {
+func newTmplFalta[T any](errFmt string, opts *options) tmplFalta[T] {
+	for tag, translation := range opts.translations {
+		template.Must(opts.parseTmpl(translation, tag))
+	}
+
+	if opts.public != "" {
+		template.Must(opts.parseTmpl(opts.public, ""))
+	}
+
+	opts.render = opts.renderTmpl
+	opts.redactPayload = redactor(reflect.TypeOf((*T)(nil)).Elem(), opts.redact)
+
 	return tmplFalta[T]{
 		errFmt: errFmt,
-		tmpl:   template.Must(template.New("tmplFactoryFmt").Parse(errFmt)),
+		tmpl:   template.Must(opts.parseTmpl(errFmt, "")),
+		opts:   opts,
 	}
 }
 
+// parseTmpl parses a template declaration, or its translation in the language identified by tag.
+func (o *options) parseTmpl(text, tag string) (*template.Template, error) {
+	tmpl, err := template.New("tmplFactoryFmt").
+		Funcs(templateFuncs(tag)).
+		Funcs(template.FuncMap{sanitizeFunc: o.sanitizeTemplateFunc()}).
+		Parse(text)
+
+	if err == nil && o.sanitize != nil {
+		sanitizeTemplate(tmpl)
+	}
+
+	return tmpl, err
+}
+
+func (o *options) renderTmpl(text, tag string, payload any) (string, error) {
+	if payload == nil {
+		return text, nil
+	}
+
+	tmpl, err := o.parseTmpl(text, tag)
+
+	if err != nil {
+		return "", err
+	}
+
+	builder := new(strings.Builder)
+
+	if err = tmpl.Execute(builder, payload); err != nil {
+		return "", err
+	}
+
+	return builder.String(), nil
+}
+
 // New constructs a new error by executing the Falta's template with the struct provided. It panics if the template
 // returns an error with it executes.
 func (f tmplFalta[T]) New(vs ...T) Falta {
+	return report(EventNew, f.build(vs).withID().withCaller())
+}
+
+func (f tmplFalta[T]) build(vs []T) Falta {
 	if len(vs) == 0 {
-		return Falta{errFmt: f.errFmt, error: f}
+		return Falta{errFmt: f.errFmt, msg: f.errFmt, opts: f.opts, details: &details{built: true}}
+	}
+
+	d := &details{payload: vs[0], built: true}
+
+	if f.opts.redactPayload != nil {
+		d.payload, d.raw = f.opts.redactPayload(vs[0]), vs[0]
 	}
 
 	builder := new(strings.Builder)
 
-	if err := f.tmpl.Execute(builder, vs[0]); err != nil {
+	if err := f.tmpl.Execute(builder, d.payload); err != nil {
 		panic(fmt.Errorf("falta: cannot execute template: %w", err))
 	}
 
-	return Falta{errFmt: f.errFmt, error: errors.New(builder.String())}
+	return Falta{errFmt: f.errFmt, msg: builder.String(), opts: f.opts, details: d}
 }
 
 func (f tmplFalta[T]) Extend(other Factory[T]) ExtendableFactory[T] {
@@ -147,7 +386,7 @@ func (f tmplFalta[T]) Extend(other Factory[T]) ExtendableFactory[T] {
 		panic(fmt.Errorf("falta: tmpl factories can only be extended by other tmpl factories with the same type"))
 	}
 
-	return newTmplFalta[T](f.errFmt + " " + v.errFmt)
+	return newTmplFalta[T](f.errFmt+" "+v.errFmt, f.opts.extend(v.opts))
 }
 
 func (f tmplFalta[T]) Error() string {
@@ -164,20 +403,34 @@ func (f tmplFalta[T]) Is(err error) bool {
 
 type fmtFalta struct {
 	errFmt string
+	opts   *options
 }
 
-func newFmtFactory(errFmt string) fmtFalta {
+func newFmtFactory(errFmt string, opts *options) fmtFalta {
+	opts.render = opts.renderFmt
+	panicIfRedacting(opts)
+
 	return fmtFalta{
 		errFmt: errFmt,
+		opts:   opts,
 	}
 }
 
 func (f fmtFalta) New(vs ...any) Falta {
+	return report(EventNew, f.build(vs).withID().withCaller())
+}
+
+func (f fmtFalta) build(vs []any) Falta {
 	if len(vs) == 0 {
-		return Falta{errFmt: f.errFmt, error: f}
+		return Falta{errFmt: f.errFmt, msg: f.errFmt, opts: f.opts, details: &details{built: true}}
 	}
 
-	return Falta{errFmt: f.errFmt, error: fmt.Errorf(f.errFmt, vs...)}
+	return Falta{
+		errFmt:  f.errFmt,
+		msg:     fmt.Errorf(f.errFmt, f.opts.sanitizeArgs(vs)...).Error(),
+		opts:    f.opts,
+		details: &details{payload: slices.Clone(vs), built: true},
+	}
 }
 
 func (f fmtFalta) Extend(other Factory[any]) ExtendableFactory[any] {
@@ -187,7 +440,25 @@ func (f fmtFalta) Extend(other Factory[any]) ExtendableFactory[any] {
 		panic(fmt.Errorf("falta: fmt factories can only be extended by other fmt factories"))
 	}
 
-	return newFmtFactory(f.errFmt + " " + v.errFmt)
+	return newFmtFactory(f.errFmt+" "+v.errFmt, f.opts.extend(v.opts))
+}
+
+// renderFmt renders a translation or public message of a printf factory. Unlike the declaration, these are expected
+// to leave out some of the arguments, so fmt's report of the unused ones is dropped.
+func (o *options) renderFmt(format, _ string, payload any) (string, error) {
+	args, _ := payload.([]any)
+
+	if len(args) == 0 {
+		return format, nil
+	}
+
+	msg := fmt.Errorf(format, o.sanitizeArgs(args)...).Error()
+
+	if i := strings.LastIndex(msg, "%!(EXTRA "); i >= 0 && !strings.Contains(format, "%!(EXTRA ") {
+		msg = msg[:i]
+	}
+
+	return msg, nil
 }
 
 func (f fmtFalta) Error() string {
@@ -375,6 +375,32 @@ func TestCapture(t *testing.T) {
 	})
 }
 
+func TestCaptureFunc(t *testing.T) {
+	as := assert.New(t)
+	errCannotOpenFile := falta.Newf("open: cannot open file %s")
+
+	var events []falta.Event
+	defer falta.AddHook(func(e falta.Event) { events = append(events, e) })()
+
+	open := func(name string) (file *os.File, err error) {
+		defer falta.Capture(&err, errCannotOpenFile, name)
+		return os.Open(name)
+	}
+
+	f, err := open(os.DevNull)
+	as.NoError(err)
+	as.NoError(f.Close())
+	as.Empty(events, "nothing is built when there is no error")
+
+	_, err = open("does-not-exist.txt")
+	as.ErrorIs(err, errCannotOpenFile)
+	as.ErrorIs(err, os.ErrNotExist)
+	as.Contains(err.Error(), "open: cannot open file does-not-exist.txt")
+	as.Len(events, 1)
+	as.Equal(falta.EventCapture, events[0].Kind)
+	as.False(events[0].Built, "the capture is reported rather than the error")
+}
+
 func TestUnwrap(t *testing.T) {
 	as := assert.New(t)
 	factory := falta.Newf("test error: %s")
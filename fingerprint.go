@@ -0,0 +1,64 @@
+package falta
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"runtime"
+	"strings"
+)
+
+// WithStack makes the factory record where each of its errors is built: the caller of New, or for NewError, where the
+// error is declared. The location shows up in %+v and Chain, and its function is part of the error's Fingerprint, so
+// errors from the same factory raised in different places are grouped apart.
+func WithStack() Option {
+	return func(o *options) {
+		o.stack = true
+	}
+}
+
+// withCaller records the caller of the function that called it, if the factory records stacks.
+func (f Falta) withCaller() Falta {
+	if !f.options().stack {
+		return f
+	}
+
+	var pcs [1]uintptr
+
+	if runtime.Callers(3, pcs[:]) == 0 {
+		return f
+	}
+
+	f.details = f.details.clone()
+	f.details.caller = pcs[0]
+	return f
+}
+
+// caller returns where this layer of a chain was built, or the zero runtime.Frame if that was not recorded.
+func (f Falta) caller() runtime.Frame {
+	if f.details == nil || f.details.caller == 0 {
+		return runtime.Frame{}
+	}
+
+	frame, _ := runtime.CallersFrames([]uintptr{f.details.caller}).Next()
+	return frame
+}
+
+// Fingerprint returns a stable hash of err's chain for grouping occurrences of the same error, such as in an error
+// tracker. It is built from the shape of the chain: the declaration and code of each Falta, the function it was built
+// in if its factory was declared with WithStack, and the type of every other error. Interpolated data, annotations
+// and attributes are left out, so every occurrence of an error gets the same fingerprint, in any process.
+func Fingerprint(err error) string {
+	builder := new(strings.Builder)
+
+	for _, frame := range Chain(err) {
+		if frame.Falta {
+			fmt.Fprintf(builder, "%d falta %q %q %q\n", frame.Depth, frame.Format, frame.Code, frame.Caller.Function)
+		} else {
+			fmt.Fprintf(builder, "%d %T\n", frame.Depth, frame.Err)
+		}
+	}
+
+	sum := sha256.Sum256([]byte(builder.String()))
+	return hex.EncodeToString(sum[:8])
+}
@@ -0,0 +1,64 @@
+package falta_test
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"strings"
+	"testing"
+
+	"github.com/a20r/falta"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFingerprint(t *testing.T) {
+	as := assert.New(t)
+
+	errNotFound := falta.Newf("user store: no user with id %d", falta.WithCode("USER_NOT_FOUND"))
+	errLoad := falta.NewError("api: cannot load user")
+
+	first := errLoad.Wrap(errNotFound.New(42)).Annotate("cache miss").With("shard", 3)
+	second := errLoad.Wrap(errNotFound.New(7))
+
+	as.Equal(falta.Fingerprint(first), falta.Fingerprint(second), "interpolated data and notes are ignored")
+	as.Len(falta.Fingerprint(first), 16)
+	as.Equal("ee7bb4f6dbeb08e6", falta.Fingerprint(second), "fingerprints must be stable across processes")
+
+	as.NotEqual(falta.Fingerprint(first), falta.Fingerprint(errNotFound.New(42)), "the whole chain counts")
+	as.NotEqual(falta.Fingerprint(errLoad.Wrap(errors.New("x"))), falta.Fingerprint(errLoad.Wrap(&fs.PathError{Op: "open", Path: "x", Err: fs.ErrNotExist})),
+		"other errors count by type")
+	as.Equal(falta.Fingerprint(errLoad.Wrap(errors.New("disk 1 full"))), falta.Fingerprint(errLoad.Wrap(errors.New("disk 2 full"))))
+	as.Equal(falta.Fingerprint(fmt.Errorf("get %d: %w", 1, second)), falta.Fingerprint(fmt.Errorf("get %d: %w", 2, second)))
+}
+
+func newFromHere(factory falta.Factory[any]) falta.Falta {
+	return factory.New("here")
+}
+
+func newFromThere(factory falta.Factory[any]) falta.Falta {
+	return factory.New("there")
+}
+
+func TestFingerprint_Stack(t *testing.T) {
+	as := assert.New(t)
+
+	withStack := falta.Newf("store: %s failed", falta.WithStack())
+	withoutStack := falta.Newf("store: %s failed")
+
+	as.NotEqual(falta.Fingerprint(newFromHere(withStack)), falta.Fingerprint(newFromThere(withStack)),
+		"the top frame tells the call sites apart")
+	as.Equal(falta.Fingerprint(newFromHere(withStack)), falta.Fingerprint(newFromHere(withStack)))
+	as.Equal(falta.Fingerprint(newFromHere(withoutStack)), falta.Fingerprint(newFromThere(withoutStack)))
+
+	frame := falta.Chain(newFromHere(withStack))[0]
+	as.Equal("github.com/a20r/falta_test.newFromHere", frame.Caller.Function)
+	as.True(strings.HasSuffix(frame.Caller.File, "fingerprint_test.go"))
+	as.Contains(fmt.Sprintf("%+v", newFromHere(withStack)), "at: github.com/a20r/falta_test.newFromHere (")
+
+	sentinel := falta.NewError("store: closed", falta.WithStack())
+	as.Equal("github.com/a20r/falta_test.TestFingerprint_Stack", falta.Chain(sentinel)[0].Caller.Function,
+		"NewError records where it is declared")
+
+	m := falta.NewM("store: {{.op}} failed", falta.WithStack())
+	as.Equal("github.com/a20r/falta_test.TestFingerprint_Stack", falta.Chain(m.New(falta.M{"op": "get"}))[0].Caller.Function)
+}
@@ -0,0 +1,153 @@
+package falta
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Format implements fmt.Formatter.
+//
+//   - %s and %v print the message, the same as Error.
+//   - %q prints the message quoted, and %x and %X print it hex-encoded, as they would any error.
+//   - %+v prints the message followed by an indented breakdown of the error: its declaration, code, payload,
+//     annotations and attributes, then each cause in turn. Causes are followed through other wrappers, such as
+//     fmt.Errorf with %w, so a Falta anywhere in the chain gets its own breakdown.
+//   - %#v prints a Go-syntax-like representation of the error, which is handy in test failures.
+//
+// The wrappers made by fmt.Errorf do not implement fmt.Formatter themselves, so %+v on one of them prints only its
+// message. Use errors.As to reach the Falta and format that instead.
+func (f Falta) Format(s fmt.State, verb rune) {
+	switch {
+	case verb == 'v' && s.Flag('+'):
+		writeDetailed(s, f)
+	case verb == 'v' && s.Flag('#'):
+		writeGoSyntax(s, f)
+	case verb == 'v':
+		fmt.Fprintf(s, fmt.FormatString(s, 's'), f.Error())
+	case verb == 's' || verb == 'q' || verb == 'x' || verb == 'X':
+		fmt.Fprintf(s, fmt.FormatString(s, verb), f.Error())
+	default:
+		fmt.Fprintf(s, "%%!%c(falta.Falta=%s)", verb, f.Error())
+	}
+}
+
+func (f Falta) payload() any {
+	if f.details == nil {
+		return nil
+	}
+
+	return f.details.payload
+}
+
+// writeDetailed writes each frame of err's chain, with the breakdown of every Falta beneath its message.
+func writeDetailed(w io.Writer, err error) {
+	for i, frame := range Chain(err) {
+		indent := strings.Repeat("  ", frame.Depth)
+		msg := strings.ReplaceAll(frame.Err.Error(), "\n", "\n"+indent+"  ")
+
+		if i == 0 {
+			fmt.Fprintf(w, "%s", msg)
+		} else {
+			fmt.Fprintf(w, "\n%scause: %s", indent, msg)
+		}
+
+		if !frame.Falta {
+			continue
+		}
+
+		fmt.Fprintf(w, "\n%s  declaration: %q", indent, frame.Format)
+
+		if frame.Code != "" {
+			fmt.Fprintf(w, "\n%s  code: %s", indent, frame.Code)
+		}
+
+		if frame.ID != "" {
+			fmt.Fprintf(w, "\n%s  id: %s", indent, frame.ID)
+		}
+
+		if frame.Caller.Function != "" {
+			fmt.Fprintf(w, "\n%s  at: %s (%s:%d)", indent, frame.Caller.Function, frame.Caller.File, frame.Caller.Line)
+		}
+
+		if frame.Severity != 0 {
+			fmt.Fprintf(w, "\n%s  severity: %s", indent, frame.Severity)
+		}
+
+		if frame.Payload != nil {
+			fmt.Fprintf(w, "\n%s  payload: %+v", indent, frame.Payload)
+		}
+
+		if len(frame.Annotations) > 0 {
+			fmt.Fprintf(w, "\n%s  annotations: %q", indent, frame.Annotations)
+		}
+
+		if len(frame.Attrs) > 0 {
+			fmt.Fprintf(w, "\n%s  attributes: %v", indent, frame.Attrs)
+		}
+	}
+}
+
+func writeGoSyntax(w io.Writer, f Falta) {
+	fmt.Fprintf(w, "falta.Falta{Format:%q, Message:%q", f.errFmt, f.msg)
+
+	if code := f.options().code; code != "" {
+		fmt.Fprintf(w, ", Code:%q", code)
+	}
+
+	if payload := f.payload(); payload != nil {
+		fmt.Fprintf(w, ", Payload:%#v", payload)
+	}
+
+	if annotations := f.notes(noteAnnotation); len(annotations) > 0 {
+		fmt.Fprintf(w, ", Annotations:%#v", noteAnnotations(annotations))
+	}
+
+	if attrs := f.notes(noteAttr); len(attrs) > 0 {
+		fmt.Fprintf(w, ", Attrs:%#v", noteAttrs(attrs))
+	}
+
+	if cause, ok := asLayer(f.wrappedErr); ok {
+		fmt.Fprint(w, ", Cause:")
+		writeGoSyntax(w, cause)
+	} else if f.wrappedErr != nil {
+		fmt.Fprintf(w, ", Cause:%T(%q)", f.wrappedErr, f.wrappedErr.Error())
+	}
+
+	fmt.Fprint(w, "}")
+}
+
+// causes returns the errors err wraps, whether it wraps one error or several.
+func causes(err error) []error {
+	switch v := err.(type) { //nolint:errorlint // inspects the Unwrap methods of a single layer
+	case interface{ Unwrap() []error }:
+		return v.Unwrap()
+	default:
+		if cause := errors.Unwrap(err); cause != nil {
+			return []error{cause}
+		}
+
+		return nil
+	}
+}
+
+func noteAnnotations(notes []note) []string {
+	var annotations []string
+
+	for _, n := range notes {
+		annotations = append(annotations, n.annotation)
+	}
+
+	return annotations
+}
+
+func noteAttrs(notes []note) []Attr {
+	var attrs []Attr
+
+	for _, n := range notes {
+		attrs = append(attrs, n.attr)
+	}
+
+	return attrs
+}
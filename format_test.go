@@ -0,0 +1,79 @@
+package falta_test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/a20r/falta"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFormat_Message(t *testing.T) {
+	as := assert.New(t)
+	err := falta.Newf("user store: no user with id %d").New(42).Annotate("cache miss").With("tenant", "acme")
+
+	as.Equal("user store: no user with id 42: cache miss", fmt.Sprintf("%v", err))
+	as.Equal("user store: no user with id 42: cache miss", fmt.Sprintf("%s", err))
+	as.Equal(`"user store: no user with id 42: cache miss"`, fmt.Sprintf("%q", err))
+	as.Equal("user store", fmt.Sprintf("%.10s", err), "precision and width are honored")
+	as.Equal(fmt.Sprintf("%x", err.Error()), fmt.Sprintf("%x", err))
+	as.Equal("75 73 65 72", fmt.Sprintf("% .4X", err))
+	as.Equal("%!d(falta.Falta=user store: no user with id 42: cache miss)", fmt.Sprintf("%d", err))
+}
+
+func TestFormat_Detailed(t *testing.T) {
+	type circle struct {
+		Radius float64
+	}
+
+	inner := falta.New[circle]("invalid circle: radius ({{.Radius}}) <= 0").
+		New(circle{Radius: -1}).
+		Annotate("from user input").
+		With("field", "radius")
+	outer := falta.Newf("render: cannot draw %s").New("scene").Annotatef("after %d frames", 3).
+		Wrap(fmt.Errorf("layout: %w", inner))
+
+	expected := `render: cannot draw scene: after 3 frames: layout: invalid circle: radius (-1) <= 0: from user input
+  declaration: "render: cannot draw %s"
+  payload: [scene]
+  annotations: ["after 3 frames"]
+  cause: layout: invalid circle: radius (-1) <= 0: from user input
+    cause: invalid circle: radius (-1) <= 0: from user input
+      declaration: "invalid circle: radius ({{.Radius}}) <= 0"
+      payload: {Radius:-1}
+      annotations: ["from user input"]
+      attributes: [field=radius]`
+
+	assert.Equal(t, expected, fmt.Sprintf("%+v", outer))
+}
+
+func TestFormat_DetailedJoinedCauses(t *testing.T) {
+	err := falta.NewError("shutdown failed").Wrap(errors.Join(errors.New("db: close"), falta.NewError("queue: close")))
+
+	expected := `shutdown failed: db: close
+  queue: close
+  declaration: "shutdown failed"
+  cause: db: close
+    queue: close
+    cause: db: close
+    cause: queue: close
+      declaration: "queue: close"`
+
+	assert.Equal(t, expected, fmt.Sprintf("%+v", err))
+}
+
+func TestFormat_GoSyntax(t *testing.T) {
+	as := assert.New(t)
+
+	inner := falta.NewError("db: closed").Annotate("during shutdown")
+	err := falta.Newf("repo: cannot load %d").New(7).With("table", "users").Wrap(inner)
+
+	as.Equal(`falta.Falta{Format:"repo: cannot load %d", Message:"repo: cannot load 7", Payload:[]interface {}{7}, `+
+		`Attrs:[]falta.Attr{falta.Attr{Key:"table", Value:"users"}}, `+
+		`Cause:falta.Falta{Format:"db: closed", Message:"db: closed", Annotations:[]string{"during shutdown"}}}`,
+		fmt.Sprintf("%#v", err))
+
+	as.Equal(`falta.Falta{Format:"boom", Message:"boom", Cause:*errors.errorString("cause")}`,
+		fmt.Sprintf("%#v", falta.NewError("boom").Wrap(errors.New("cause"))))
+}
@@ -0,0 +1,127 @@
+package falta
+
+import (
+	"slices"
+	"sync"
+	"sync/atomic"
+)
+
+// EventKind is what happened to an error that a Hook is told about.
+type EventKind int
+
+const (
+	// EventNew is reported when a factory's New or NewCtx builds an error.
+	EventNew EventKind = iota
+
+	// EventWrap is reported when Wrap wraps an error.
+	EventWrap
+
+	// EventAnnotate is reported when Annotate or Annotatef adds an annotation.
+	EventAnnotate
+
+	// EventCapture is reported when Capture or CaptureCtx wraps the error a function returns. It is reported instead
+	// of EventWrap.
+	EventCapture
+)
+
+// String returns the event kind's name, such as "wrap".
+func (k EventKind) String() string {
+	switch k {
+	case EventNew:
+		return "new"
+	case EventWrap:
+		return "wrap"
+	case EventAnnotate:
+		return "annotate"
+	case EventCapture:
+		return "capture"
+	default:
+		return "unknown"
+	}
+}
+
+// Event describes something that happened to an error, as reported to a Hook.
+type Event struct {
+	// Kind is what happened.
+	Kind EventKind
+
+	// Factory is the declaration string of the factory that built the error, which identifies it.
+	Factory string
+
+	// Code is the code the factory was declared with, if any.
+	Code string
+
+	// Err is the error as it is after the event.
+	Err Falta
+
+	// Built reports whether Err was built by a factory's New or NewCtx, which is always the case for EventNew. When
+	// it is set for EventWrap or EventCapture, the error was already reported when it was built, so a hook that
+	// counts errors should not count it again.
+	Built bool
+}
+
+// Hook observes errors as they are built and wrapped, such as to count or sample them. Hooks are called synchronously
+// by the goroutine that caused the event, so they must be safe to call from several goroutines at once and should
+// return quickly.
+type Hook func(Event)
+
+type hookEntry struct {
+	hook Hook
+}
+
+// hooks holds the registered hooks. It is replaced rather than modified, so reporting an event only needs an atomic
+// load, and costs next to nothing when no hook is registered.
+var hooks atomic.Pointer[[]*hookEntry]
+
+var hooksMu sync.Mutex
+
+// AddHook registers a hook that is called for every event on every error, in the order the hooks were added. It
+// returns a function that removes the hook again, which is handy for tests.
+func AddHook(hook Hook) (remove func()) {
+	entry := &hookEntry{hook: hook}
+	updateHooks(func(entries []*hookEntry) []*hookEntry {
+		return append(entries, entry)
+	})
+
+	var once sync.Once
+
+	return func() {
+		once.Do(func() {
+			updateHooks(func(entries []*hookEntry) []*hookEntry {
+				return slices.DeleteFunc(entries, func(e *hookEntry) bool { return e == entry })
+			})
+		})
+	}
+}
+
+func updateHooks(update func([]*hookEntry) []*hookEntry) {
+	hooksMu.Lock()
+	defer hooksMu.Unlock()
+
+	var entries []*hookEntry
+
+	if current := hooks.Load(); current != nil {
+		entries = slices.Clone(*current)
+	}
+
+	entries = update(entries)
+	hooks.Store(&entries)
+}
+
+// report tells the registered hooks about an event on f and returns f, so it can wrap a return value.
+func report(kind EventKind, f Falta) Falta {
+	entries := hooks.Load()
+
+	if entries == nil || len(*entries) == 0 {
+		return f
+	}
+
+	built := f.details != nil && f.details.built
+	event := Event{Kind: kind, Factory: f.errFmt, Code: f.options().code, Err: f, Built: built}
+
+	for _, entry := range *entries {
+		entry.hook(event)
+	}
+
+	return f
+}
@@ -0,0 +1,95 @@
+package falta_test
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/a20r/falta"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAddHook(t *testing.T) {
+	as := assert.New(t)
+
+	errNotFound := falta.Newf("hooks test: no user with id %d")
+	errLoad := falta.NewError("hooks test: cannot load user")
+
+	var events []falta.Event
+	remove := falta.AddHook(func(e falta.Event) {
+		events = append(events, e)
+	})
+	defer remove()
+
+	notFound := errNotFound.New(42)
+	annotated := notFound.Annotate("cache miss")
+	wrapped := errLoad.Wrap(annotated)
+
+	captured := func() (err error) {
+		defer errLoad.Capture(&err)
+		return errors.New("db: connection lost")
+	}()
+
+	as.Len(events, 4)
+	as.Equal(falta.Event{Kind: falta.EventNew, Factory: "hooks test: no user with id %d", Err: notFound, Built: true}, events[0])
+	as.Equal(falta.Event{Kind: falta.EventAnnotate, Factory: "hooks test: no user with id %d", Err: annotated,
+		Built: true}, events[1])
+	as.Equal(falta.Event{Kind: falta.EventWrap, Factory: "hooks test: cannot load user", Err: wrapped}, events[2])
+	as.Equal(falta.EventCapture, events[3].Kind, "Capture is reported instead of Wrap")
+	as.Equal(captured, events[3].Err)
+
+	remove()
+	remove()
+	errNotFound.New(7)
+	as.Len(events, 4, "a removed hook is not called again, and removing it twice is harmless")
+}
+
+func TestAddHook_Order(t *testing.T) {
+	as := assert.New(t)
+	var calls []string
+
+	removeFirst := falta.AddHook(func(falta.Event) { calls = append(calls, "first") })
+	removeSecond := falta.AddHook(func(falta.Event) { calls = append(calls, "second") })
+	defer removeSecond()
+
+	falta.NewM("hooks test: {{.op}}").New(falta.M{"op": "get"})
+	removeFirst()
+	falta.NewM("hooks test: {{.op}}").New(falta.M{"op": "put"})
+
+	as.Equal([]string{"first", "second", "second"}, calls)
+}
+
+func TestAddHook_Concurrent(t *testing.T) {
+	errNotFound := falta.Newf("hooks test: no user with id %d")
+
+	var count atomic.Int64
+	remove := falta.AddHook(func(e falta.Event) {
+		if e.Factory == "hooks test: no user with id %d" {
+			count.Add(1)
+		}
+	})
+	defer remove()
+
+	var wg sync.WaitGroup
+
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			for j := 0; j < 100; j++ {
+				_ = errNotFound.New(j)
+				falta.AddHook(func(falta.Event) {})()
+			}
+		}()
+	}
+
+	wg.Wait()
+	assert.Equal(t, int64(800), count.Load())
+}
+
+func TestEventKind_String(t *testing.T) {
+	assert.Equal(t, "capture", falta.EventCapture.String())
+}
@@ -0,0 +1,101 @@
+package falta
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"sync/atomic"
+	"time"
+)
+
+// WithID gives each error the factory builds a unique instance ID, made by the generator set with SetIDGenerator, so
+// that a single occurrence can be found again from a support ticket. The ID is kept by Wrap, Annotate, Capture and the
+// other methods that add to an error, and is read back with ID.
+//
+// NOTE: Errors from New get their ID when they are built. A NewError error is declared once and shared, so it gets its
+// ID when it is first wrapped, annotated or given an attribute, which is when an occurrence of it is made.
+func WithID() Option {
+	return func(o *options) {
+		o.id = true
+	}
+}
+
+// IDGenerator makes instance IDs for WithID. It must be safe to call from several goroutines at once.
+type IDGenerator func() string
+
+var idGenerator atomic.Pointer[IDGenerator]
+
+// SetIDGenerator sets the generator used for the IDs of every factory declared with WithID. It defaults to
+// TimeSortedID.
+func SetIDGenerator(gen IDGenerator) {
+	idGenerator.Store(&gen)
+}
+
+// RandomID returns 128 random bits as 32 hexadecimal digits.
+func RandomID() string {
+	var b [16]byte
+	_, _ = rand.Read(b[:])
+	return hex.EncodeToString(b[:])
+}
+
+// crockford is the Crockford base32 alphabet, which leaves out letters that are easily misread, such as I, L and O.
+const crockford = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+// TimeSortedID returns an ID in the style of a ULID: 26 characters of Crockford base32 encoding the current time in
+// milliseconds followed by 80 random bits. IDs made in different milliseconds sort in the order they were made.
+func TimeSortedID() string {
+	var b [16]byte
+	binary.BigEndian.PutUint64(b[:8], uint64(time.Now().UnixMilli())<<16)
+	_, _ = rand.Read(b[6:])
+
+	// 128 bits are encoded 5 at a time, most significant first, with the first character holding the top 3.
+	hi, lo := binary.BigEndian.Uint64(b[:8]), binary.BigEndian.Uint64(b[8:])
+	id := make([]byte, 26)
+
+	for i := 25; i >= 0; i-- {
+		id[i] = crockford[lo&0x1f]
+		lo = lo>>5 | hi<<59
+		hi >>= 5
+	}
+
+	return string(id)
+}
+
+func newID() string {
+	if gen := idGenerator.Load(); gen != nil {
+		return (*gen)()
+	}
+
+	return TimeSortedID()
+}
+
+// withID gives the error an instance ID if its factory asks for one and it does not have one yet.
+func (f Falta) withID() Falta {
+	if !f.options().id || f.id() != "" {
+		return f
+	}
+
+	f.details = f.details.clone()
+	f.details.id = newID()
+	return f
+}
+
+func (f Falta) id() string {
+	if f.details == nil {
+		return ""
+	}
+
+	return f.details.id
+}
+
+// ID returns the instance ID of the outermost Falta in err's chain that has one, or "" if none does.
+func ID(err error) string {
+	for ; err != nil; err = errors.Unwrap(err) {
+		if f, ok := asLayer(err); ok && f.id() != "" {
+			return f.id()
+		}
+	}
+
+	return ""
+}
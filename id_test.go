@@ -0,0 +1,96 @@
+package falta_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/a20r/falta"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestID(t *testing.T) {
+	as := assert.New(t)
+
+	errNotFound := falta.Newf("user store: no user with id %d", falta.WithID())
+	errLoad := falta.NewError("api: cannot load user")
+
+	first, second := errNotFound.New(42), errNotFound.New(42)
+	as.NotEmpty(falta.ID(first))
+	as.NotEqual(falta.ID(first), falta.ID(second), "every occurrence gets its own ID")
+
+	as.Equal(falta.ID(first), falta.ID(first.Annotate("cache miss").With("shard", 3)))
+	as.Equal(falta.ID(first), falta.ID(errLoad.Wrap(first)), "wrapping keeps the ID")
+	as.Equal(falta.ID(first), falta.ID(fmt.Errorf("handler: %w", first)))
+	as.Equal(falta.ID(first), falta.ID(first.Localize("de")))
+
+	captured := func() (err error) {
+		defer errLoad.Capture(&err)
+		return first
+	}()
+	as.Equal(falta.ID(first), falta.ID(captured))
+
+	as.Empty(falta.ID(errLoad.Wrap(errors.New("plain"))))
+	as.Empty(falta.ID(errors.New("plain")))
+	as.Empty(falta.ID(nil))
+	as.NotContains(first.Error(), falta.ID(first), "the ID is not part of the message")
+	as.ErrorIs(first, errNotFound)
+}
+
+func TestID_Sentinel(t *testing.T) {
+	as := assert.New(t)
+	errClosed := falta.NewError("store: closed", falta.WithID())
+
+	as.Empty(falta.ID(errClosed), "a declared sentinel is not an occurrence")
+
+	first, second := errClosed.Annotate("on write"), errClosed.Wrap(errors.New("eof"))
+	as.NotEmpty(falta.ID(first))
+	as.NotEmpty(falta.ID(second))
+	as.NotEqual(falta.ID(first), falta.ID(second))
+	as.Equal(falta.ID(first), falta.ID(first.Annotate("again")))
+	as.ErrorIs(first, errClosed)
+}
+
+func TestID_Outputs(t *testing.T) {
+	as := assert.New(t)
+	err := falta.NewM("store: {{.op}} failed", falta.WithID()).New(falta.M{"op": "get"})
+
+	buf := new(bytes.Buffer)
+	slog.New(slog.NewJSONHandler(buf, nil)).Error("request failed", "error", err)
+
+	var record struct {
+		Error map[string]any `json:"error"`
+	}
+	as.NoError(json.Unmarshal(buf.Bytes(), &record))
+	as.Equal(falta.ID(err), record.Error["id"])
+
+	as.Contains(fmt.Sprintf("%+v", err), "id: "+falta.ID(err))
+	as.Equal(falta.ID(err), falta.Chain(err)[0].ID)
+}
+
+func TestIDGenerators(t *testing.T) {
+	as := assert.New(t)
+
+	as.Regexp(regexp.MustCompile(`^[0-9a-f]{32}$`), falta.RandomID())
+	as.NotEqual(falta.RandomID(), falta.RandomID())
+
+	var ids []string
+
+	for i := 0; i < 3; i++ {
+		ids = append(ids, falta.TimeSortedID())
+		time.Sleep(2 * time.Millisecond)
+	}
+
+	as.Regexp(regexp.MustCompile(`^[0-9A-HJKMNP-TV-Z]{26}$`), ids[0])
+	as.True(sort.StringsAreSorted(ids), "IDs from different milliseconds sort by time")
+
+	defer falta.SetIDGenerator(falta.TimeSortedID)
+	falta.SetIDGenerator(func() string { return "fixed" })
+	as.Equal("fixed", falta.ID(falta.Newf("x %d", falta.WithID()).New(1)))
+}
@@ -0,0 +1,316 @@
+package falta
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"math"
+	"path"
+	"reflect"
+	"strings"
+	"sync"
+	"text/template"
+)
+
+// WithTranslation adds a translation of the factory's declaration for the language identified by a BCP 47 tag, such
+// as "de" or "pt-BR". The translation uses the same syntax as the declaration: a printf format string for Newf and
+// NewError, and a text/template for New and NewM. Printf translations can reorder arguments with explicit indexes,
+// such as %[2]s.
+//
+// NOTE: Template translations are parsed with the declaration, so a malformed one panics there, not in Localize.
+func WithTranslation(tag, translation string) Option {
+	return func(o *options) {
+		if o.translations == nil {
+			o.translations = make(map[string]string)
+		}
+
+		o.translations[canonicalTag(tag)] = translation
+	}
+}
+
+// Localize returns the error with its message rendered again, from the payload it was built with, in the language
+// identified by a BCP 47 tag. Translations come from the factory's WithTranslation options first, then from the
+// registered catalogs. When there is no translation for the tag, its parent tags are tried in turn ("de-CH", then
+// "de"), and failing those the message stays in the language of the declaration.
+//
+// A Falta wrapped directly by the error is localized too. Annotations, attributes and other errors are left as they
+// are, and the result still matches its factory with errors.Is.
+func (f Falta) Localize(tag string) Falta {
+	if msg, ok := f.options().localize(f.errFmt, tag, f.payload()); ok {
+		f.msg = msg
+	}
+
+	cause, ok := asLayer(f.wrappedErr)
+
+	if !ok {
+		return f
+	}
+
+	localized := cause.Localize(tag)
+	f.details = f.details.clone()
+
+	for i := len(f.details.notes) - 1; i >= 0; i-- {
+		if f.details.notes[i].kind == noteCause {
+			f.details.notes[i].cause = localized
+			break
+		}
+	}
+
+	f.wrappedErr = localized
+	return f
+}
+
+// localize renders the payload with the first translation found for the tag, reporting false if there is none or it
+// cannot be rendered.
+func (o *options) localize(errFmt, tag string, payload any) (string, bool) {
+	if o.render == nil {
+		return "", false
+	}
+
+	for _, candidate := range tagFallbacks(tag) {
+		translation, ok := o.translations[candidate]
+
+		if !ok {
+			translation, ok = catalogs.lookup(candidate, errFmt, o.code)
+		}
+
+		if !ok {
+			continue
+		}
+
+		msg, err := o.render(translation, candidate, payload)
+		return msg, err == nil
+	}
+
+	return "", false
+}
+
+// catalogs holds the translations registered with RegisterCatalog and LoadCatalogs.
+var catalogs = &catalogRegistry{byTag: make(map[string]map[string]string)}
+
+type catalogRegistry struct {
+	mu    sync.RWMutex
+	byTag map[string]map[string]string
+}
+
+func (r *catalogRegistry) add(tag string, translations map[string]string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	tag = canonicalTag(tag)
+
+	if r.byTag[tag] == nil {
+		r.byTag[tag] = make(map[string]string, len(translations))
+	}
+
+	for key, translation := range translations {
+		r.byTag[tag][key] = translation
+	}
+}
+
+func (r *catalogRegistry) lookup(tag, errFmt, code string) (string, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if translation, ok := r.byTag[tag][errFmt]; ok {
+		return translation, true
+	}
+
+	if code == "" {
+		return "", false
+	}
+
+	translation, ok := r.byTag[tag][code]
+	return translation, ok
+}
+
+// RegisterCatalog adds translations for the language identified by a BCP 47 tag. Each key is either a factory's
+// declaration string or its code, and each value is a translation written the same way a WithTranslation translation
+// for that factory would be. Registering a key again replaces its translation.
+func RegisterCatalog(tag string, translations map[string]string) {
+	catalogs.add(tag, translations)
+}
+
+// LoadCatalogs registers every JSON file in fsys matching the path.Match pattern as a catalog. The tag is taken from
+// the file name, so "locales/de-CH.json" holds the Swiss German translations. Each file is a single object mapping
+// declaration strings or codes to translations. It pairs well with embed.FS:
+//
+//	//go:embed locales/*.json
+//	var locales embed.FS
+//
+//	func init() {
+//		if err := falta.LoadCatalogs(locales, "locales/*.json"); err != nil {
+//			panic(err)
+//		}
+//	}
+func LoadCatalogs(fsys fs.FS, pattern string) error {
+	names, err := fs.Glob(fsys, pattern)
+
+	if err != nil {
+		return fmt.Errorf("falta: invalid catalog pattern %q: %w", pattern, err)
+	}
+
+	for _, name := range names {
+		if err := loadCatalog(fsys, name); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func loadCatalog(fsys fs.FS, name string) error {
+	data, err := fs.ReadFile(fsys, name)
+
+	if err != nil {
+		return fmt.Errorf("falta: cannot read catalog %s: %w", name, err)
+	}
+
+	var translations map[string]string
+
+	if err = json.Unmarshal(data, &translations); err != nil {
+		return fmt.Errorf("falta: cannot decode catalog %s: %w", name, err)
+	}
+
+	RegisterCatalog(strings.TrimSuffix(path.Base(name), path.Ext(name)), translations)
+	return nil
+}
+
+// canonicalTag normalizes a BCP 47 tag for comparison. Tags are case-insensitive, and "_" is a common stand-in for
+// "-" in locale names.
+func canonicalTag(tag string) string {
+	return strings.ToLower(strings.ReplaceAll(tag, "_", "-"))
+}
+
+// tagFallbacks returns the tag followed by each of its parents, most specific first.
+func tagFallbacks(tag string) []string {
+	tag = canonicalTag(tag)
+	fallbacks := []string{tag}
+
+	for i := strings.LastIndex(tag, "-"); i > 0; i = strings.LastIndex(tag, "-") {
+		tag = tag[:i]
+		fallbacks = append(fallbacks, tag)
+	}
+
+	return fallbacks
+}
+
+// PluralRule picks which of a message's plural forms to use for the count n, as an index into the forms passed to
+// the plural template function.
+type PluralRule func(n float64) int
+
+// pluralRules maps base language tags to their plural rules. Languages that are not listed use the English rule.
+var pluralRules = struct {
+	mu    sync.RWMutex
+	rules map[string]PluralRule
+}{
+	rules: map[string]PluralRule{
+		// one, other
+		"en": func(n float64) int { return boolIndex(n != 1) },
+		// one (0 and 1), other
+		"fr": func(n float64) int { return boolIndex(n >= 2) },
+		"pt": func(n float64) int { return boolIndex(n >= 2) },
+		// one, few, many
+		"ru": slavicPlural,
+		"uk": slavicPlural,
+		"pl": func(n float64) int {
+			i := int64(n)
+
+			switch {
+			case i == 1:
+				return 0
+			case i%10 >= 2 && i%10 <= 4 && (i%100 < 12 || i%100 > 14):
+				return 1
+			default:
+				return 2
+			}
+		},
+		// a single form
+		"ja": func(float64) int { return 0 },
+		"ko": func(float64) int { return 0 },
+		"zh": func(float64) int { return 0 },
+	},
+}
+
+// RegisterPluralRule sets the plural rule for a language, replacing the built-in one if there is one. The rule
+// applies to the tag and every tag beneath it.
+func RegisterPluralRule(tag string, rule PluralRule) {
+	pluralRules.mu.Lock()
+	defer pluralRules.mu.Unlock()
+
+	pluralRules.rules[canonicalTag(tag)] = rule
+}
+
+func pluralRule(tag string) PluralRule {
+	pluralRules.mu.RLock()
+	defer pluralRules.mu.RUnlock()
+
+	for _, candidate := range tagFallbacks(tag) {
+		if rule, ok := pluralRules.rules[candidate]; ok {
+			return rule
+		}
+	}
+
+	return pluralRules.rules["en"]
+}
+
+// templateFuncs returns the functions available to template declarations and translations in the language
+// identified by tag. The declaration itself uses the English plural rule.
+//
+// plural picks a form by count, following the language's plural rule: {{plural .count "file" "files"}}. The count can
+// be any integer or floating point number. If the rule asks for a form beyond those provided, the last one is used.
+func templateFuncs(tag string) template.FuncMap {
+	rule := pluralRule(tag)
+
+	return template.FuncMap{
+		"plural": func(count any, forms ...string) (string, error) {
+			n, err := toFloat(count)
+
+			if err != nil {
+				return "", err
+			}
+
+			if len(forms) == 0 {
+				return "", fmt.Errorf("plural: no forms given for %v", count)
+			}
+
+			return forms[min(max(rule(n), 0), len(forms)-1)], nil
+		},
+	}
+}
+
+func slavicPlural(n float64) int {
+	i := int64(n)
+
+	switch {
+	case i%10 == 1 && i%100 != 11:
+		return 0
+	case i%10 >= 2 && i%10 <= 4 && (i%100 < 12 || i%100 > 14):
+		return 1
+	default:
+		return 2
+	}
+}
+
+func boolIndex(b bool) int {
+	if b {
+		return 1
+	}
+
+	return 0
+}
+
+func toFloat(v any) (float64, error) {
+	rv := reflect.ValueOf(v)
+
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return math.Abs(float64(rv.Int())), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return float64(rv.Uint()), nil
+	case reflect.Float32, reflect.Float64:
+		return math.Abs(rv.Float()), nil
+	default:
+		return 0, fmt.Errorf("plural: %v (%T) is not a number", v, v)
+	}
+}
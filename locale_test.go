@@ -0,0 +1,145 @@
+package falta_test
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/a20r/falta"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLocalize(t *testing.T) {
+	as := assert.New(t)
+	factory := falta.Newf("user store: no user with id %d in %s",
+		falta.WithTranslation("de", "Benutzerspeicher: kein Benutzer mit ID %d in %s"),
+		falta.WithTranslation("ja", "ユーザーストア: %[2]s にID %[1]d のユーザーはいません"),
+	)
+
+	err := factory.New(42, "eu").Annotate("cache miss")
+
+	as.EqualError(err.Localize("de"), "Benutzerspeicher: kein Benutzer mit ID 42 in eu: cache miss")
+	as.EqualError(err.Localize("de-CH"), "Benutzerspeicher: kein Benutzer mit ID 42 in eu: cache miss",
+		"a regional tag should fall back to its language")
+	as.EqualError(err.Localize("DE_at"), "Benutzerspeicher: kein Benutzer mit ID 42 in eu: cache miss",
+		"tags are case-insensitive and accept underscores")
+	as.EqualError(err.Localize("ja"), "ユーザーストア: eu にID 42 のユーザーはいません: cache miss")
+	as.EqualError(err.Localize("fr"), "user store: no user with id 42 in eu: cache miss",
+		"a missing translation should fall back to the declaration")
+	as.ErrorIs(err.Localize("de"), factory, "localizing should not change identity")
+	as.EqualError(err, "user store: no user with id 42 in eu: cache miss", "Localize must not mutate the error")
+}
+
+func TestLocalize_Template(t *testing.T) {
+	type quota struct {
+		Files int
+		Limit int
+	}
+
+	as := assert.New(t)
+	factory := falta.New[quota]("upload: {{.Files}} {{plural .Files \"file\" \"files\"}} over the limit of {{.Limit}}",
+		falta.WithTranslation("fr", "envoi : {{.Files}} {{plural .Files \"fichier\" \"fichiers\"}} au-delà de {{.Limit}}"),
+		falta.WithTranslation("ru", "загрузка: {{.Files}} {{plural .Files \"файл\" \"файла\" \"файлов\"}} сверх {{.Limit}}"),
+	)
+
+	as.EqualError(factory.New(quota{Files: 1, Limit: 10}), "upload: 1 file over the limit of 10")
+	as.EqualError(factory.New(quota{Files: 0, Limit: 10}), "upload: 0 files over the limit of 10")
+	as.EqualError(factory.New(quota{Files: 0, Limit: 10}).Localize("fr"), "envoi : 0 fichier au-delà de 10")
+	as.EqualError(factory.New(quota{Files: 2, Limit: 10}).Localize("fr"), "envoi : 2 fichiers au-delà de 10")
+	as.EqualError(factory.New(quota{Files: 21, Limit: 10}).Localize("ru"), "загрузка: 21 файл сверх 10")
+	as.EqualError(factory.New(quota{Files: 3, Limit: 10}).Localize("ru"), "загрузка: 3 файла сверх 10")
+	as.EqualError(factory.New(quota{Files: 12, Limit: 10}).Localize("ru"), "загрузка: 12 файлов сверх 10")
+}
+
+func TestLocalize_WrappedFalta(t *testing.T) {
+	as := assert.New(t)
+
+	inner := falta.NewError("db: connection lost", falta.WithTranslation("es", "bd: conexión perdida"))
+	outer := falta.Newf("api: cannot load user %d", falta.WithTranslation("es", "api: no se puede cargar el usuario %d"))
+
+	err := outer.New(7).Wrap(inner).Annotate("giving up")
+
+	localized := err.Localize("es")
+	as.EqualError(localized, "api: no se puede cargar el usuario 7: bd: conexión perdida: giving up")
+	as.ErrorIs(localized, inner)
+	as.ErrorIs(localized, outer)
+	as.EqualError(localized.Unwrap(), "bd: conexión perdida")
+}
+
+func TestLocalize_Extend(t *testing.T) {
+	as := assert.New(t)
+
+	base := falta.NewM("call failed: [code={{.code}}]",
+		falta.WithTranslation("de", "Aufruf fehlgeschlagen: [Code={{.code}}]"),
+		falta.WithTranslation("es", "llamada fallida: [código={{.code}}]"))
+	extended := base.Extend(falta.NewM("because {{.reason}}", falta.WithTranslation("de", "weil {{.reason}}")))
+
+	err := extended.New(falta.M{"code": 503, "reason": "upstream"})
+
+	as.EqualError(err.Localize("de"), "Aufruf fehlgeschlagen: [Code=503] weil upstream")
+	as.EqualError(err.Localize("es"), "call failed: [code=503] because upstream",
+		"a language only one side translates cannot be used for the extended factory")
+}
+
+func TestLocalize_Catalogs(t *testing.T) {
+	as := assert.New(t)
+
+	fsys := fstest.MapFS{
+		"locales/it.json":    {Data: []byte(`{"catalog test: disk %s is full": "test del catalogo: il disco %s è pieno"}`)},
+		"locales/pt-BR.json": {Data: []byte(`{"CATALOG_TEST_QUOTA": "teste de catálogo: cota de {{.user}} excedida"}`)},
+		"locales/README.md":  {Data: []byte("not a catalog")},
+	}
+
+	as.NoError(falta.LoadCatalogs(fsys, "locales/*.json"))
+
+	byFormat := falta.Newf("catalog test: disk %s is full")
+	as.EqualError(byFormat.New("sda1").Localize("it"), "test del catalogo: il disco sda1 è pieno")
+
+	byCode := falta.NewM("catalog test: quota for {{.user}} exceeded", falta.WithCode("CATALOG_TEST_QUOTA"))
+	as.EqualError(byCode.New(falta.M{"user": "ana"}).Localize("pt-br"), "teste de catálogo: cota de ana excedida")
+	as.EqualError(byCode.New(falta.M{"user": "ana"}).Localize("pt"), "catalog test: quota for ana exceeded",
+		"a language does not fall forward to its regional variants")
+
+	own := falta.Newf("catalog test: disk %s is full", falta.WithTranslation("it", "il disco %s è pieno"))
+	as.EqualError(own.New("sda1").Localize("it"), "il disco sda1 è pieno",
+		"a factory's own translation takes precedence over catalogs")
+
+	falta.RegisterCatalog("it", map[string]string{"catalog test: {{.broken": "catalog test: {{.rotto"})
+	broken := falta.NewM("catalog test: {{.broken}}")
+	as.EqualError(broken.New(falta.M{"broken": "x"}).Localize("it"), "catalog test: x",
+		"a catalog entry that cannot be rendered falls back to the declaration")
+}
+
+func TestLoadCatalogs_Errors(t *testing.T) {
+	as := assert.New(t)
+
+	as.Error(falta.LoadCatalogs(fstest.MapFS{"bad.json": {Data: []byte("{")}}, "*.json"))
+	as.Error(falta.LoadCatalogs(fstest.MapFS{}, "["), "a malformed pattern is an error")
+}
+
+func TestWithTranslation_PanicsOnInvalidTemplate(t *testing.T) {
+	assert.Panics(t, func() {
+		falta.NewM("ok {{.a}}", falta.WithTranslation("de", "kaputt {{.a"))
+	})
+}
+
+func TestRegisterPluralRule(t *testing.T) {
+	as := assert.New(t)
+
+	// In this made-up language, counts under 10 are "few" and the rest are "many".
+	falta.RegisterPluralRule("x-test", func(n float64) int {
+		if n < 10 {
+			return 0
+		}
+
+		return 1
+	})
+
+	factory := falta.NewM("{{.n}} {{plural .n \"item\" \"items\"}}",
+		falta.WithTranslation("x-test", "{{.n}} {{plural .n \"few\" \"many\"}}"))
+
+	as.EqualError(factory.New(falta.M{"n": 3}).Localize("x-test"), "3 few")
+	as.EqualError(factory.New(falta.M{"n": 30}).Localize("x-test-variant"), "30 many")
+	as.Panics(func() {
+		_ = factory.New(falta.M{"n": "three"})
+	}, "plural needs a number")
+}
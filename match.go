@@ -0,0 +1,110 @@
+package falta
+
+import "errors"
+
+// Matcher runs the first of a list of cases that matches an error, like a switch statement over errors.Is. Make one
+// with Match.
+type Matcher struct {
+	err     error
+	matched bool
+}
+
+// Match starts matching err against cases:
+//
+//	falta.Match(err).
+//		Case(ErrUserNotFound, func(f falta.Falta) { http.Error(w, "not found", http.StatusNotFound) }).
+//		Case(ErrUnauthorized, func(f falta.Falta) { http.Error(w, "unauthorized", http.StatusUnauthorized) }).
+//		Default(func(err error) { http.Error(w, "internal error", http.StatusInternalServerError) })
+//
+// A nil error matches no case, and does not run the default either.
+func Match(err error) *Matcher {
+	return &Matcher{err: err}
+}
+
+// Case runs fn if no earlier case matched and the error matches target with errors.Is. Target is usually a factory,
+// and fn receives the Falta in the error's chain that the factory built, with its payload. For any other target, fn
+// receives the outermost Falta in the chain, or the zero Falta if there is none.
+func (m *Matcher) Case(target error, fn func(f Falta)) *Matcher {
+	if f, ok := m.match(target); ok {
+		fn(f)
+	}
+
+	return m
+}
+
+// CaseData is Case for a factory declared with New[T] or NewM: fn also receives the value the matched error was built
+// from, unredacted. If the error was built without one, fn receives the zero T. It is a function rather than a method
+// of Matcher because Go methods cannot have type parameters.
+func CaseData[T any](m *Matcher, factory Factory[T], fn func(data T, f Falta)) *Matcher {
+	if f, ok := m.match(factory); ok {
+		data, _ := f.Unredacted().(T)
+		fn(data, f)
+	}
+
+	return m
+}
+
+// Default runs fn with the error if no case matched it.
+func (m *Matcher) Default(fn func(err error)) {
+	if m.err != nil && !m.matched {
+		fn(m.err)
+	}
+}
+
+// Matched reports whether a case matched the error.
+func (m *Matcher) Matched() bool {
+	return m.matched
+}
+
+// match reports whether the case for target should run, and finds the Falta to pass it.
+func (m *Matcher) match(target error) (Falta, bool) {
+	if m.matched || m.err == nil || !errors.Is(m.err, target) {
+		return Falta{}, false
+	}
+
+	m.matched = true
+	declaration, isFactory := factoryDeclaration(target)
+	var outermost *Falta
+
+	for _, frame := range Chain(m.err) {
+		f, ok := asLayer(frame.Err)
+
+		if !ok {
+			continue
+		}
+
+		if isFactory && f.errFmt == declaration {
+			return f, true
+		}
+
+		if outermost == nil {
+			outermost = &f
+		}
+	}
+
+	if outermost == nil {
+		return Falta{}, true
+	}
+
+	return *outermost, true
+}
+
+// factoryDeclaration returns the declaration of a falta factory, or of the factory that built a Falta.
+func factoryDeclaration(target error) (string, bool) {
+	switch v := target.(type) { //nolint:errorlint // the target itself is inspected, not its chain
+	case Falta:
+		return v.errFmt, true
+	case interface{ declaration() string }:
+		return v.declaration(), true
+	default:
+		return "", false
+	}
+}
+
+func (f tmplFalta[T]) declaration() string {
+	return f.errFmt
+}
+
+func (f fmtFalta) declaration() string {
+	return f.errFmt
+}
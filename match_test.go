@@ -0,0 +1,95 @@
+package falta_test
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/a20r/falta"
+	"github.com/stretchr/testify/assert"
+)
+
+type quota struct {
+	User  string
+	Limit int
+}
+
+var (
+	errMatchNotFound = falta.Newf("match test: no user with id %d")
+	errMatchQuota    = falta.New[quota]("match test: {{.User}} is over the limit of {{.Limit}}")
+	errMatchClosed   = falta.NewError("match test: store closed")
+	errMatchLoad     = falta.NewError("match test: cannot load user")
+)
+
+func handle(err error) string {
+	var result string
+
+	m := falta.Match(err).
+		Case(errMatchNotFound, func(f falta.Falta) {
+			result = fmt.Sprintf("not found: %v", falta.Chain(f)[0].Payload)
+		}).
+		Case(errMatchClosed, func(f falta.Falta) {
+			result = "closed: " + f.Error()
+		})
+
+	falta.CaseData(m, errMatchQuota, func(q quota, f falta.Falta) {
+		result = fmt.Sprintf("quota: %s/%d", q.User, q.Limit)
+	}).
+		Case(io.EOF, func(f falta.Falta) {
+			result = "eof under " + f.Error()
+		}).
+		Default(func(err error) {
+			result = "default: " + err.Error()
+		})
+
+	return result
+}
+
+func TestMatch(t *testing.T) {
+	as := assert.New(t)
+
+	as.Equal("not found: [42]", handle(errMatchNotFound.New(42)))
+	as.Equal("not found: [42]", handle(errMatchLoad.Wrap(errMatchNotFound.New(42))),
+		"the case receives the layer its factory built")
+	as.Equal("closed: match test: store closed: on write", handle(errMatchClosed.Annotate("on write")))
+	as.Equal("quota: ana/10", handle(fmt.Errorf("handler: %w", errMatchQuota.New(quota{User: "ana", Limit: 10}))))
+	as.Equal("eof under match test: cannot load user: EOF", handle(errMatchLoad.Wrap(io.EOF)),
+		"other targets receive the outermost Falta")
+	as.Equal("default: plain", handle(errors.New("plain")))
+	as.Empty(handle(nil), "a nil error runs nothing")
+}
+
+func TestMatch_FirstCaseWins(t *testing.T) {
+	as := assert.New(t)
+	var calls []string
+
+	err := errMatchLoad.Wrap(errMatchClosed)
+	m := falta.Match(err).
+		Case(errMatchClosed, func(falta.Falta) { calls = append(calls, "closed") }).
+		Case(errMatchLoad, func(falta.Falta) { calls = append(calls, "load") })
+	m.Default(func(error) { calls = append(calls, "default") })
+
+	as.Equal([]string{"closed"}, calls)
+	as.True(m.Matched())
+	as.False(falta.Match(errors.New("plain")).Case(errMatchClosed, func(falta.Falta) {}).Matched())
+}
+
+func TestCaseData_Unredacted(t *testing.T) {
+	as := assert.New(t)
+	factory := falta.NewM("match test: login failed for {{.email}}", falta.WithRedaction(falta.RedactFull, "email"))
+
+	var got falta.M
+	falta.CaseData(falta.Match(factory.New(falta.M{"email": "ana@example.com"})), factory, func(data falta.M, _ falta.Falta) {
+		got = data
+	})
+	as.Equal(falta.M{"email": "ana@example.com"}, got, "handlers receive the data as it was passed to New")
+
+	var zero quota
+	called := false
+	falta.CaseData(falta.Match(errMatchQuota.New()), errMatchQuota, func(data quota, _ falta.Falta) {
+		zero, called = data, true
+	})
+	as.True(called)
+	as.Equal(quota{}, zero)
+}
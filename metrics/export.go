@@ -0,0 +1,68 @@
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Exporter writes a registry's snapshot in a format another system can read.
+type Exporter interface {
+	// ContentType returns the MIME type of the format, for HTTP responses.
+	ContentType() string
+
+	// Export writes the stats to w.
+	Export(w io.Writer, stats []Stats) error
+}
+
+// Prometheus exports stats in the Prometheus text exposition format, as two metrics labeled by declaration and code:
+// a counter of errors and a gauge of when each factory was last seen, in Unix seconds.
+type Prometheus struct {
+	// Namespace prefixes the metric names. It defaults to "falta".
+	Namespace string
+}
+
+// ContentType returns the content type of version 0.0.4 of the text format.
+func (Prometheus) ContentType() string {
+	return "text/plain; version=0.0.4; charset=utf-8"
+}
+
+// Export writes the stats in the text format.
+func (p Prometheus) Export(w io.Writer, stats []Stats) error {
+	namespace := p.Namespace
+
+	if namespace == "" {
+		namespace = "falta"
+	}
+
+	bw := bufio.NewWriter(w)
+
+	fmt.Fprintf(bw, "# HELP %s_errors_total Errors built, wrapped or captured, per factory.\n", namespace)
+	fmt.Fprintf(bw, "# TYPE %s_errors_total counter\n", namespace)
+
+	for _, s := range stats {
+		fmt.Fprintf(bw, "%s_errors_total%s %d\n", namespace, labels(s), s.Count)
+	}
+
+	fmt.Fprintf(bw, "# HELP %s_error_last_seen_seconds When each factory's most recent error was counted.\n", namespace)
+	fmt.Fprintf(bw, "# TYPE %s_error_last_seen_seconds gauge\n", namespace)
+
+	for _, s := range stats {
+		fmt.Fprintf(bw, "%s_error_last_seen_seconds%s %.3f\n", namespace, labels(s),
+			float64(s.LastSeen.UnixMilli())/1000)
+	}
+
+	return bw.Flush()
+}
+
+func labels(s Stats) string {
+	return fmt.Sprintf(`{declaration="%s",code="%s"}`, escapeLabel(s.Declaration), escapeLabel(s.Code))
+}
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// escapeLabel escapes a label value as the text format requires.
+func escapeLabel(value string) string {
+	return labelEscaper.Replace(value)
+}
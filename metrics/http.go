@@ -0,0 +1,63 @@
+package metrics
+
+import (
+	"html/template"
+	"net/http"
+)
+
+var pageTmpl = template.Must(template.New("page").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>falta errors</title>
+<style>
+body { font-family: sans-serif; }
+table { border-collapse: collapse; }
+th, td { border: 1px solid #ccc; padding: 4px 8px; text-align: left; vertical-align: top; }
+td.count { text-align: right; }
+code { white-space: pre-wrap; }
+</style>
+</head>
+<body>
+<h1>falta errors</h1>
+<table>
+<tr><th>Declaration</th><th>Code</th><th>Count</th><th>Last seen</th><th>Recent examples</th></tr>
+{{- range .}}
+<tr>
+<td><code>{{.Declaration}}</code></td>
+<td>{{.Code}}</td>
+<td class="count">{{.Count}}</td>
+<td>{{.LastSeen.UTC.Format "2006-01-02 15:04:05.000 MST"}}</td>
+<td>{{range .Examples}}<code>{{.}}</code><br>{{end}}</td>
+</tr>
+{{- else}}
+<tr><td colspan="5">No errors counted yet.</td></tr>
+{{- end}}
+</table>
+</body>
+</html>
+`))
+
+// Handler returns an http.Handler that shows what the registry has counted as an HTML table, with a row per factory:
+// its declaration, code, count, when it was last seen and its most recent examples. Examples are rendered error
+// messages, which can hold user data, so mount it where only operators can reach it.
+func (r *Registry) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+
+		if err := pageTmpl.Execute(w, r.Snapshot()); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+	})
+}
+
+// HandlerFor returns an http.Handler that serves what the registry has counted in the exporter's format.
+func (r *Registry) HandlerFor(exp Exporter) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", exp.ContentType())
+
+		if err := exp.Export(w, r.Snapshot()); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+	})
+}
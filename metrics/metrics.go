@@ -0,0 +1,192 @@
+// Package metrics counts how often each falta factory's errors happen. A Registry observes errors through a falta
+// hook, and publishes what it counted through expvar, an HTML debug page, and Exporters such as Prometheus.
+//
+//	reg := metrics.NewRegistry(metrics.Options{Examples: 5})
+//	defer reg.Install()()
+//
+//	reg.Publish("falta")
+//	http.Handle("/debug/falta", reg.Handler())
+//	http.Handle("/metrics/falta", reg.HandlerFor(metrics.Prometheus{}))
+package metrics
+
+import (
+	"encoding/json"
+	"expvar"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/a20r/falta"
+)
+
+// Options configures a Registry.
+type Options struct {
+	// Examples is how many of the most recent errors of each factory are kept to show as examples. Zero keeps none.
+	Examples int
+
+	// Now returns the current time. It defaults to time.Now.
+	Now func() time.Time
+}
+
+// Stats is what a Registry counted for a single factory.
+type Stats struct {
+	// Declaration is the factory's declaration string, which identifies it.
+	Declaration string `json:"declaration"`
+
+	// Code is the code the factory was declared with, if any.
+	Code string `json:"code,omitempty"`
+
+	// Count is how many errors the factory built, wrapped or captured.
+	Count uint64 `json:"count"`
+
+	// LastSeen is when the factory's most recent error was counted, in UTC.
+	LastSeen time.Time `json:"last_seen"`
+
+	// Examples are the rendered messages of the factory's most recent errors, newest first.
+	Examples []string `json:"examples,omitempty"`
+}
+
+// Registry counts the errors of every factory. Counting happens on the goroutine that builds the error, with atomic
+// counters and a short lock only to keep examples. The zero Registry is not usable; make one with NewRegistry.
+type Registry struct {
+	opts Options
+
+	mu        sync.RWMutex
+	factories map[string]*factory
+}
+
+type factory struct {
+	declaration string
+	code        string
+	count       atomic.Uint64
+	lastSeen    atomic.Int64 // Unix nanoseconds
+
+	mu       sync.Mutex
+	examples []falta.Falta // a ring of the most recent errors, rendered only when read
+	next     int
+}
+
+// NewRegistry returns an empty registry. It does not count anything until it is installed.
+func NewRegistry(opts Options) *Registry {
+	if opts.Now == nil {
+		opts.Now = time.Now
+	}
+
+	return &Registry{opts: opts, factories: make(map[string]*factory)}
+}
+
+// Install registers the registry as a falta hook, so that it counts every error built by New or NewCtx, wrapped by
+// Wrap, or captured by Capture or CaptureCtx. It returns a function that uninstalls it.
+func (r *Registry) Install() (uninstall func()) {
+	return falta.AddHook(r.Observe)
+}
+
+// Observe counts the event. Install calls it for every falta event; it is exported so that a Registry can also be
+// fed from a hook of your own. Annotations do not make a new error, so EventAnnotate is not counted, and neither is
+// wrapping or capturing with an error that was counted when New built it, so that ErrX.New(id).Wrap(err) counts once.
+// An error deferred with the falta.Capture function is only built, and counted, when the capture fires.
+func (r *Registry) Observe(e falta.Event) {
+	if e.Kind == falta.EventAnnotate || (e.Kind != falta.EventNew && e.Built) {
+		return
+	}
+
+	f := r.factory(e.Factory, e.Code)
+	f.count.Add(1)
+	f.lastSeen.Store(r.opts.Now().UnixNano())
+
+	if r.opts.Examples <= 0 {
+		return
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if len(f.examples) < r.opts.Examples {
+		f.examples = append(f.examples, e.Err)
+	} else {
+		f.examples[f.next] = e.Err
+	}
+
+	f.next = (f.next + 1) % r.opts.Examples
+}
+
+func (r *Registry) factory(declaration, code string) *factory {
+	r.mu.RLock()
+	f, ok := r.factories[declaration]
+	r.mu.RUnlock()
+
+	if ok {
+		return f
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if f, ok = r.factories[declaration]; !ok {
+		f = &factory{declaration: declaration, code: code}
+		r.factories[declaration] = f
+	}
+
+	return f
+}
+
+// Snapshot returns what the registry has counted so far, one Stats per factory, sorted by declaration.
+func (r *Registry) Snapshot() []Stats {
+	r.mu.RLock()
+	factories := make([]*factory, 0, len(r.factories))
+
+	for _, f := range r.factories {
+		factories = append(factories, f)
+	}
+
+	r.mu.RUnlock()
+
+	stats := make([]Stats, 0, len(factories))
+
+	for _, f := range factories {
+		stats = append(stats, f.stats())
+	}
+
+	sort.Slice(stats, func(i, j int) bool {
+		return stats[i].Declaration < stats[j].Declaration
+	})
+
+	return stats
+}
+
+func (f *factory) stats() Stats {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	s := Stats{
+		Declaration: f.declaration,
+		Code:        f.code,
+		Count:       f.count.Load(),
+		LastSeen:    time.Unix(0, f.lastSeen.Load()).UTC(),
+	}
+
+	for i := 1; i <= len(f.examples); i++ {
+		s.Examples = append(s.Examples, f.examples[(f.next-i+len(f.examples))%len(f.examples)].Error())
+	}
+
+	return s
+}
+
+// String returns the snapshot as JSON, which makes a Registry an expvar.Var.
+func (r *Registry) String() string {
+	data, err := json.Marshal(r.Snapshot())
+
+	if err != nil {
+		return "null"
+	}
+
+	return string(data)
+}
+
+// Publish publishes the registry with expvar under the name provided, so that it shows up at /debug/vars.
+//
+// NOTE: Like expvar.Publish, it panics if the name is already in use.
+func (r *Registry) Publish(name string) {
+	expvar.Publish(name, r)
+}
@@ -0,0 +1,161 @@
+package metrics_test
+
+import (
+	"errors"
+	"expvar"
+	"fmt"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/a20r/falta"
+	"github.com/a20r/falta/metrics"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	errNotFound = falta.Newf("metrics test: no user with id %d", falta.WithCode("USER_NOT_FOUND"))
+	errLoad     = falta.NewError("metrics test: cannot load <user>")
+)
+
+func fixedClock() func() time.Time {
+	return func() time.Time {
+		return time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	}
+}
+
+func record(t *testing.T, opts metrics.Options) *metrics.Registry {
+	reg := metrics.NewRegistry(opts)
+	t.Cleanup(reg.Install())
+
+	for i := 1; i <= 3; i++ {
+		_ = errNotFound.New(i).Annotate("annotations are not counted")
+	}
+
+	_ = errNotFound.New(4).Wrap(errors.New("cache: miss")) // counted once, when New builds it
+
+	_ = errLoad.Wrap(errors.New("db: connection lost"))
+
+	_ = func() (err error) {
+		defer errLoad.Capture(&err)
+		return errors.New("db: timeout")
+	}()
+
+	return reg
+}
+
+func TestRegistry_Snapshot(t *testing.T) {
+	as := assert.New(t)
+	reg := record(t, metrics.Options{Examples: 2, Now: fixedClock()})
+
+	as.Equal([]metrics.Stats{
+		{
+			Declaration: "metrics test: cannot load <user>",
+			Count:       2,
+			LastSeen:    fixedClock()(),
+			Examples:    []string{"metrics test: cannot load <user>: db: timeout", "metrics test: cannot load <user>: db: connection lost"},
+		},
+		{
+			Declaration: "metrics test: no user with id %d",
+			Code:        "USER_NOT_FOUND",
+			Count:       4,
+			LastSeen:    fixedClock()(),
+			Examples:    []string{"metrics test: no user with id 4", "metrics test: no user with id 3"},
+		},
+	}, reg.Snapshot())
+}
+
+func TestRegistry_DeferredCapture(t *testing.T) {
+	as := assert.New(t)
+	reg := metrics.NewRegistry(metrics.Options{Now: fixedClock()})
+	t.Cleanup(reg.Install())
+
+	load := func(id int, cause error) (err error) {
+		defer falta.Capture(&err, errNotFound, id)
+		return cause
+	}
+
+	for i := 0; i < 10; i++ {
+		as.NoError(load(i, nil))
+	}
+
+	as.Empty(reg.Snapshot(), "a capture that does not fire is not counted")
+
+	as.Error(load(10, errors.New("db: connection lost")))
+	as.Equal(uint64(1), reg.Snapshot()[0].Count)
+}
+
+func TestRegistry_Uninstall(t *testing.T) {
+	reg := metrics.NewRegistry(metrics.Options{})
+	reg.Install()()
+
+	_ = errNotFound.New(1)
+	assert.Empty(t, reg.Snapshot())
+}
+
+// published counts the runs of TestRegistry_Expvar, since expvar only lets a name be published once.
+var published int
+
+func TestRegistry_Expvar(t *testing.T) {
+	as := assert.New(t)
+	reg := record(t, metrics.Options{Now: fixedClock()})
+
+	published++
+	name := fmt.Sprintf("falta_metrics_test_%d", published)
+
+	reg.Publish(name)
+	as.Same(reg, expvar.Get(name))
+	as.JSONEq(`[
+		{"declaration": "metrics test: cannot load <user>", "count": 2, "last_seen": "2024-03-01T12:00:00Z"},
+		{"declaration": "metrics test: no user with id %d", "code": "USER_NOT_FOUND", "count": 4, "last_seen": "2024-03-01T12:00:00Z"}
+	]`, reg.String())
+}
+
+func TestRegistry_Handler(t *testing.T) {
+	as := assert.New(t)
+	reg := record(t, metrics.Options{Examples: 1, Now: fixedClock()})
+
+	rec := httptest.NewRecorder()
+	reg.Handler().ServeHTTP(rec, httptest.NewRequest("GET", "/debug/falta", nil))
+
+	body := rec.Body.String()
+	as.Equal("text/html; charset=utf-8", rec.Header().Get("Content-Type"))
+	as.Contains(body, "<td><code>metrics test: no user with id %d</code></td>")
+	as.Contains(body, "<td>USER_NOT_FOUND</td>")
+	as.Contains(body, `<td class="count">4</td>`)
+	as.Contains(body, "<td>2024-03-01 12:00:00.000 UTC</td>")
+	as.Contains(body, "<code>metrics test: no user with id 4</code>")
+	as.Contains(body, "metrics test: cannot load &lt;user&gt;", "messages are escaped")
+
+	empty := httptest.NewRecorder()
+	metrics.NewRegistry(metrics.Options{}).Handler().ServeHTTP(empty, httptest.NewRequest("GET", "/", nil))
+	as.Contains(empty.Body.String(), "No errors counted yet.")
+}
+
+func TestPrometheus(t *testing.T) {
+	as := assert.New(t)
+	reg := record(t, metrics.Options{Now: fixedClock()})
+
+	rec := httptest.NewRecorder()
+	reg.HandlerFor(metrics.Prometheus{}).ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+
+	as.Equal("text/plain; version=0.0.4; charset=utf-8", rec.Header().Get("Content-Type"))
+	as.Equal(`# HELP falta_errors_total Errors built, wrapped or captured, per factory.
+# TYPE falta_errors_total counter
+falta_errors_total{declaration="metrics test: cannot load <user>",code=""} 2
+falta_errors_total{declaration="metrics test: no user with id %d",code="USER_NOT_FOUND"} 4
+# HELP falta_error_last_seen_seconds When each factory's most recent error was counted.
+# TYPE falta_error_last_seen_seconds gauge
+falta_error_last_seen_seconds{declaration="metrics test: cannot load <user>",code=""} 1709294400.000
+falta_error_last_seen_seconds{declaration="metrics test: no user with id %d",code="USER_NOT_FOUND"} 1709294400.000
+`, rec.Body.String())
+}
+
+func TestPrometheus_Escaping(t *testing.T) {
+	buf := new(strings.Builder)
+	stats := []metrics.Stats{{Declaration: "quote \" backslash \\ newline \n", Count: 1}}
+
+	assert.NoError(t, metrics.Prometheus{Namespace: "app"}.Export(buf, stats))
+	assert.Contains(t, buf.String(), `app_errors_total{declaration="quote \" backslash \\ newline \n",code=""} 1`)
+}
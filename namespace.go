@@ -0,0 +1,102 @@
+package falta
+
+import (
+	"strings"
+)
+
+// Scope declares factories in a namespace, such as the package or subsystem they belong to. Make one with Namespace.
+type Scope struct {
+	names []string
+	opts  []Option
+}
+
+// Namespace returns a Scope whose factories prefix their messages with name, as in "user store: no user with id 42".
+// The options provided apply to every factory declared in the scope, before the factory's own.
+//
+// The namespace is part of each factory's identity, since it is part of the declaration, and so two packages that
+// declare the same wording in different namespaces no longer match each other's errors. It also qualifies codes, so
+// WithCode("NOT_FOUND") becomes "user store/NOT_FOUND", and registry namespaces, so WithRegistry("") registers the
+// factory under "user store".
+func Namespace(name string, opts ...Option) Scope {
+	return Scope{names: []string{name}, opts: opts}
+}
+
+// Namespace returns a scope nested in this one. Its factories are prefixed with both names, as in
+// "user store: cache: miss for 42", and its name is both joined by "/", as in "user store/cache". It has the options
+// of both scopes, the outer ones first.
+func (s Scope) Namespace(name string, opts ...Option) Scope {
+	return Scope{
+		names: append(s.names[:len(s.names):len(s.names)], name),
+		opts:  append(s.opts[:len(s.opts):len(s.opts)], opts...),
+	}
+}
+
+// Name returns the scope's name, which is the names of the scopes it is nested in and its own joined by "/".
+func (s Scope) Name() string {
+	return strings.Join(s.names, "/")
+}
+
+// prefix returns what the scope puts in front of the messages of its factories.
+func (s Scope) prefix() string {
+	return strings.Join(s.names, ": ") + ": "
+}
+
+// options builds the options of a factory declared in the scope, qualifying its code and registry namespace and
+// prefixing its translations with prefix.
+func (s Scope) options(opts []Option, prefix string) *options {
+	o := newOptions(append(s.opts[:len(s.opts):len(s.opts)], opts...))
+
+	if o.code != "" {
+		o.code = s.Name() + "/" + o.code
+	}
+
+	if o.registry != nil {
+		namespace := s.Name()
+
+		if *o.registry != "" {
+			namespace += "/" + *o.registry
+		}
+
+		o.registry = &namespace
+	}
+
+	for tag, translation := range o.translations {
+		o.translations[tag] = prefix + translation
+	}
+
+	return o
+}
+
+// Newf is falta.Newf for the scope: it declares a printf factory whose messages are prefixed with the namespace.
+func (s Scope) Newf(errFmt string, opts ...Option) ExtendableFactory[any] {
+	prefix := strings.ReplaceAll(s.prefix(), "%", "%%")
+	o := s.options(opts, prefix)
+	f := newFmtFactory(prefix+errFmt, o)
+	register(o, f.errFmt, f)
+	return f
+}
+
+// NewM is falta.NewM for the scope: it declares a template factory over a falta.M whose messages are prefixed with the
+// namespace.
+func (s Scope) NewM(errFmt string, opts ...Option) ExtendableFactory[M] {
+	return NewIn[M](s, errFmt, opts...)
+}
+
+// NewError is falta.NewError for the scope: it declares an error whose message is prefixed with the namespace.
+func (s Scope) NewError(msg string, opts ...Option) Falta {
+	panicIfStringHasVerbs(msg)
+
+	o := s.options(opts, s.prefix())
+	f := newError(s.prefix()+msg, o).withCaller()
+	register(o, f.errFmt, f)
+	return f
+}
+
+// NewIn is falta.New for a scope: it declares a template factory over T whose messages are prefixed with the
+// namespace. It is a function rather than a method of Scope because Go methods cannot have type parameters.
+func NewIn[T any](s Scope, errFmt string, opts ...Option) ExtendableFactory[T] {
+	o := s.options(opts, s.prefix())
+	f := newTmplFalta[T](s.prefix()+errFmt, o)
+	register(o, f.errFmt, f)
+	return f
+}
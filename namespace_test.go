@@ -0,0 +1,85 @@
+package falta_test
+
+import (
+	"testing"
+
+	"github.com/a20r/falta"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNamespace(t *testing.T) {
+	type lookup struct {
+		ID int
+	}
+
+	as := assert.New(t)
+	userStore := falta.Namespace("user store")
+
+	as.EqualError(userStore.Newf("no user with id %d").New(42), "user store: no user with id 42")
+	as.EqualError(userStore.NewM("no user named {{.name}}").New(falta.M{"name": "ana"}), "user store: no user named ana")
+	as.EqualError(falta.NewIn[lookup](userStore, "no user with id {{.ID}}").New(lookup{ID: 7}), "user store: no user with id 7")
+	as.EqualError(userStore.NewError("closed"), "user store: closed")
+	as.Equal("user store", userStore.Name())
+}
+
+func TestNamespace_Identity(t *testing.T) {
+	as := assert.New(t)
+
+	errUserClosed := falta.Namespace("user store").NewError("closed")
+	errOrderClosed := falta.Namespace("order store").NewError("closed")
+	errUserNotFound := falta.Namespace("user store").Newf("no record with id %d")
+	errOrderNotFound := falta.Namespace("order store").Newf("no record with id %d")
+
+	as.NotErrorIs(errUserClosed, errOrderClosed, "the same wording in different namespaces is a different error")
+	as.NotErrorIs(errUserNotFound.New(1), errOrderNotFound)
+	as.ErrorIs(errUserNotFound.New(1), errUserNotFound)
+	as.ErrorIs(errUserClosed.Annotate("on write"), errUserClosed)
+}
+
+func TestNamespace_Nested(t *testing.T) {
+	as := assert.New(t)
+
+	cache := falta.Namespace("user store", falta.WithSeverity(falta.LevelWarn)).
+		Namespace("cache", falta.WithCode("MISS"))
+
+	err := cache.Newf("miss for %d").New(42)
+	as.EqualError(err, "user store: cache: miss for 42")
+	as.Equal("user store/cache", cache.Name())
+	as.Equal("user store/cache/MISS", falta.Code(err), "codes are qualified by the namespace")
+	as.Equal(falta.LevelWarn, falta.Severity(err), "options of outer scopes apply")
+
+	other := falta.Namespace("user store").Namespace("db")
+	as.EqualError(other.NewError("timeout"), "user store: db: timeout", "sibling scopes do not share names")
+}
+
+// The registered factories are declared once, as they would be in a program, so that the test can run more than once.
+var (
+	namespaceTestScope   = falta.Namespace("namespace test 100%")
+	errNamespaceTestUser = namespaceTestScope.Newf("no user with id %d",
+		falta.WithCode("NOT_FOUND"),
+		falta.WithRegistry(""),
+		falta.WithTranslation("de", "kein Benutzer mit ID %d"),
+	)
+	_ = namespaceTestScope.Namespace("sub").NewError("closed", falta.WithRegistry("errors"))
+)
+
+func TestNamespace_Options(t *testing.T) {
+	as := assert.New(t)
+
+	err := errNamespaceTestUser.New(42)
+	as.EqualError(err, "namespace test 100%: no user with id 42", "percent signs in names are not verbs")
+	as.EqualError(err.Localize("de"), "namespace test 100%: kein Benutzer mit ID 42", "translations are prefixed too")
+
+	entry, ok := falta.Lookup("namespace test 100%/NOT_FOUND")
+	as.True(ok)
+	as.Equal("namespace test 100%", entry.Namespace)
+	as.Equal("namespace test 100%%: no user with id %d", entry.Format)
+
+	entry, ok = falta.LookupFormat("namespace test 100%: sub: closed")
+	as.True(ok)
+	as.Equal("namespace test 100%/sub/errors", entry.Namespace, "registry namespaces are qualified too")
+
+	as.Panics(func() {
+		namespaceTestScope.NewError("no %d here")
+	})
+}
@@ -0,0 +1,84 @@
+package falta
+
+import "time"
+
+// Option configures a factory at its declaration. Every Falta the factory builds shares the factory's options.
+type Option func(*options)
+
+// options holds the declaration-time settings of a factory.
+type options struct {
+	attrRendering AttrRendering
+	code          string
+	translations  map[string]string
+	public        string
+	redact        map[string]RedactMode
+	sanitize      *SanitizeLimits
+	retry         retryClass
+	backoff       time.Duration
+	severity      Level
+	stack         bool
+	id            bool
+	registry      *string // the namespace to register the factory under, if it is registered
+
+	// render renders a payload with a declaration or translation of the factory, in the language identified by tag.
+	// Each kind of factory sets its own.
+	render func(format, tag string, payload any) (string, error)
+
+	// redactPayload copies a payload with its sensitive fields masked, if the factory's payloads have any. It is kept
+	// here rather than in the factory so that the factory stays comparable.
+	redactPayload func(any) any
+}
+
+// defaultOptions is used by Falta values that were not built by a factory, such as the zero value.
+var defaultOptions = &options{}
+
+func newOptions(opts []Option) *options {
+	o := new(options)
+
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	return o
+}
+
+// extend returns the options for a factory that extends one declared with o using one declared with other. The base
+// options carry over, translations are kept for the languages both factories have one for, a public message declared
+// by other, which describes the more specific error, replaces the base one, as do a retry classification and a severity
+// declared by other, and fields redacted by either stay redacted. Stacks and IDs are recorded if either
+// factory records them, and the extended factory is not registered.
+func (o *options) extend(other *options) *options {
+	extended := *o
+	extended.translations = nil
+
+	if other.public != "" {
+		extended.public = other.public
+	}
+
+	if other.retry != retryUnset {
+		extended.retry, extended.backoff = other.retry, other.backoff
+	}
+
+	if other.severity != 0 {
+		extended.severity = other.severity
+	}
+
+	extended.stack = o.stack || other.stack
+	extended.id = o.id || other.id
+	extended.registry = nil
+	extended.redact = nil
+
+	for _, redact := range []map[string]RedactMode{o.redact, other.redact} {
+		for key, mode := range redact {
+			WithRedaction(mode, key)(&extended)
+		}
+	}
+
+	for tag, translation := range o.translations {
+		if otherTranslation, ok := other.translations[tag]; ok {
+			WithTranslation(tag, translation+" "+otherTranslation)(&extended)
+		}
+	}
+
+	return &extended
+}
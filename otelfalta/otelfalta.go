@@ -0,0 +1,69 @@
+// Package otelfalta records falta errors on OpenTelemetry spans. It lives in a module of its own so that falta does
+// not depend on OpenTelemetry.
+//
+//	func init() {
+//		falta.SetSpanRecorder(otelfalta.Recorder{})
+//	}
+package otelfalta
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/a20r/falta"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Recorder is a falta.SpanRecorder that adds an exception event to the span active in the context, with falta's
+// attributes converted to OpenTelemetry attributes.
+type Recorder struct{}
+
+var _ falta.SpanRecorder = Recorder{}
+
+// RecordError records err on the span active in ctx, if there is one and it is recording.
+func (Recorder) RecordError(ctx context.Context, err error, attrs []falta.Attr) {
+	span := trace.SpanFromContext(ctx)
+
+	if !span.IsRecording() {
+		return
+	}
+
+	kvs := make([]attribute.KeyValue, 0, len(attrs))
+
+	for _, attr := range attrs {
+		kvs = append(kvs, keyValue(attr))
+	}
+
+	span.RecordError(err, trace.WithAttributes(kvs...))
+}
+
+// keyValue converts an attribute, keeping the types OpenTelemetry supports and rendering anything else with fmt.
+func keyValue(attr falta.Attr) attribute.KeyValue {
+	switch v := attr.Value.(type) {
+	case string:
+		return attribute.String(attr.Key, v)
+	case bool:
+		return attribute.Bool(attr.Key, v)
+	case int:
+		return attribute.Int(attr.Key, v)
+	case int64:
+		return attribute.Int64(attr.Key, v)
+	case float64:
+		return attribute.Float64(attr.Key, v)
+	case []string:
+		return attribute.StringSlice(attr.Key, v)
+	case []bool:
+		return attribute.BoolSlice(attr.Key, v)
+	case []int:
+		return attribute.IntSlice(attr.Key, v)
+	case []int64:
+		return attribute.Int64Slice(attr.Key, v)
+	case []float64:
+		return attribute.Float64Slice(attr.Key, v)
+	case fmt.Stringer:
+		return attribute.Stringer(attr.Key, v)
+	default:
+		return attribute.String(attr.Key, fmt.Sprint(v))
+	}
+}
@@ -0,0 +1,60 @@
+package otelfalta_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/a20r/falta"
+	"github.com/a20r/falta/otelfalta"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestRecorder(t *testing.T) {
+	as := assert.New(t)
+
+	spans := tracetest.NewSpanRecorder()
+	tracer := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(spans)).Tracer("otelfalta_test")
+
+	falta.SetSpanRecorder(otelfalta.Recorder{})
+	defer falta.SetSpanRecorder(nil)
+
+	errLoad := falta.NewM("api: cannot load user {{.id}}", falta.WithCode("USER_LOAD"))
+
+	ctx, span := tracer.Start(context.Background(), "load")
+	err := func() (err error) {
+		defer errLoad.New(falta.M{"id": 42}).With("shard", 3).With("replicas", []string{"a", "b"}).CaptureCtx(ctx, &err)
+		return errors.New("db: connection lost")
+	}()
+	span.End()
+
+	as.Len(spans.Ended(), 1)
+	events := spans.Ended()[0].Events()
+	as.Len(events, 1)
+	as.Equal("exception", events[0].Name)
+
+	attrs := make(map[attribute.Key]attribute.Value)
+
+	for _, kv := range events[0].Attributes {
+		attrs[kv.Key] = kv.Value
+	}
+
+	as.Equal(err.Error(), attrs["exception.message"].AsString())
+	as.Equal("USER_LOAD", attrs["falta.code"].AsString())
+	as.Equal(falta.Fingerprint(err), attrs["falta.fingerprint"].AsString())
+	as.Equal("map[id:42]", attrs["falta.payload"].AsString())
+	as.Equal(int64(3), attrs["shard"].AsInt64())
+	as.Equal([]string{"a", "b"}, attrs["replicas"].AsStringSlice())
+}
+
+func TestRecorder_NoSpan(t *testing.T) {
+	falta.SetSpanRecorder(otelfalta.Recorder{})
+	defer falta.SetSpanRecorder(nil)
+
+	assert.NotPanics(t, func() {
+		_ = falta.NewCtx(context.Background(), falta.Newf("api: %d"), 1)
+	})
+}
@@ -0,0 +1,252 @@
+package falta
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"unicode/utf8"
+)
+
+// ColorMode controls whether Pretty and FprintPretty use ANSI colors.
+type ColorMode int
+
+const (
+	// ColorAuto uses colors when FprintPretty writes to a terminal and the NO_COLOR environment variable is unset.
+	// Pretty has nowhere to look, so it never uses colors in this mode. This is the default.
+	ColorAuto ColorMode = iota
+
+	// ColorAlways always uses colors.
+	ColorAlways
+
+	// ColorNever never uses colors, which is what CI logs want.
+	ColorNever
+)
+
+// PrettyOptions configures Pretty and FprintPretty. The zero value prints a plain tree of messages.
+type PrettyOptions struct {
+	// Color controls the use of ANSI colors.
+	Color ColorMode
+
+	// Width wraps lines to fit in this many columns. Zero or less disables wrapping.
+	Width int
+
+	// Codes shows the code of each falta error next to its message.
+	Codes bool
+
+	// Annotations lists the annotations of each falta error beneath its message.
+	Annotations bool
+}
+
+const (
+	ansiReset = "\x1b[0m"
+	ansiBold  = "\x1b[1m"
+	ansiDim   = "\x1b[2m"
+	ansiRed   = "\x1b[31m"
+	ansiYlw   = "\x1b[33m"
+
+	// minPrettyWidth keeps deeply nested frames readable when Width is small.
+	minPrettyWidth = 20
+)
+
+// Pretty renders err as an indented tree with one node per layer of its chain, as returned by Chain, instead of the
+// single "a: b: c" line that Error produces. Errors that wrap several others, such as those made by errors.Join,
+// branch.
+//
+//	api: cannot load user [USER_LOAD]
+//	└─ repo
+//	   └─ db: query on users failed [DB_QUERY]
+//	      │  · read replica
+//	      └─ connection reset
+func Pretty(err error, opts PrettyOptions) string {
+	return renderPretty(err, opts, opts.Color == ColorAlways)
+}
+
+// FprintPretty writes the tree rendered by Pretty to w, followed by a newline. With ColorAuto, it uses colors if w is
+// a terminal.
+func FprintPretty(w io.Writer, err error, opts PrettyOptions) error {
+	color := opts.Color == ColorAlways || (opts.Color == ColorAuto && isTerminal(w))
+	_, werr := io.WriteString(w, renderPretty(err, opts, color)+"\n")
+	return werr
+}
+
+func renderPretty(err error, opts PrettyOptions, color bool) string {
+	frames := Chain(err)
+	paint := func(code, s string) string {
+		if !color || code == "" || s == "" {
+			return s
+		}
+
+		return code + s + ansiReset
+	}
+
+	var lines []string
+
+	// more records, for each depth, whether the latest frame at that depth has siblings still to come, which is when
+	// its descendants need a vertical line in that column.
+	var more []bool
+
+	for i, frame := range frames {
+		last := isLastSibling(frames, i)
+		prefix := new(strings.Builder)
+
+		for depth := 1; depth < frame.Depth; depth++ {
+			if more[depth] {
+				prefix.WriteString("│  ")
+			} else {
+				prefix.WriteString("   ")
+			}
+		}
+
+		head, cont := prefix.String(), prefix.String()
+
+		if frame.Depth > 0 {
+			if last {
+				head, cont = head+"└─ ", cont+"   "
+			} else {
+				head, cont = head+"├─ ", cont+"│  "
+			}
+		}
+
+		more = append(more[:frame.Depth], !last)
+
+		// Wrapped lines and annotations sit between a frame and its causes, so they continue the vertical line down
+		// to the causes if there are any.
+		body := cont + "   "
+
+		if i+1 < len(frames) && frames[i+1].Depth > frame.Depth {
+			body = cont + "│  "
+		}
+
+		text, code := prettyMessage(frame), ""
+
+		if opts.Codes && frame.Code != "" {
+			code = "[" + frame.Code + "]"
+		}
+
+		msgColor := ""
+
+		switch {
+		case frame.Depth == 0:
+			msgColor = ansiBold
+		case frame.Falta:
+			msgColor = ansiRed
+		}
+
+		wrapped := wrapText(strings.TrimSpace(text+" "+code), opts.Width-utf8.RuneCountInString(body))
+
+		for j, line := range wrapped {
+			lead := paint(ansiDim, head)
+
+			if j > 0 {
+				lead = paint(ansiDim, body)
+			}
+
+			if j == len(wrapped)-1 && code != "" {
+				if msg := strings.TrimSpace(strings.TrimSuffix(line, code)); msg != "" {
+					lead += paint(msgColor, msg) + " "
+				}
+
+				lines = append(lines, lead+paint(ansiYlw, code))
+				continue
+			}
+
+			lines = append(lines, lead+paint(msgColor, line))
+		}
+
+		if !opts.Annotations {
+			continue
+		}
+
+		for _, annotation := range frame.Annotations {
+			for _, line := range wrapText("· "+annotation, opts.Width-utf8.RuneCountInString(body)) {
+				lines = append(lines, paint(ansiDim, body)+paint(ansiDim, line))
+			}
+		}
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// prettyMessage returns the text for a frame's node. Errors that only join others have no text of their own, so they
+// are labeled by how many errors they join.
+func prettyMessage(frame Frame) string {
+	if frame.Message != "" {
+		return strings.ReplaceAll(frame.Message, "\n", " ")
+	}
+
+	if n := len(causes(frame.Err)); n > 1 {
+		return fmt.Sprintf("(%d errors)", n)
+	}
+
+	return "(no message)"
+}
+
+// isLastSibling reports whether no later frame shares the parent of frames[i].
+func isLastSibling(frames []Frame, i int) bool {
+	for _, frame := range frames[i+1:] {
+		if frame.Depth < frames[i].Depth {
+			return true
+		}
+
+		if frame.Depth == frames[i].Depth {
+			return false
+		}
+	}
+
+	return true
+}
+
+// wrapText breaks s into lines of at most width runes, at spaces where it can. A width of zero or less disables
+// wrapping.
+func wrapText(s string, width int) []string {
+	if width <= 0 || utf8.RuneCountInString(s) <= width {
+		return []string{s}
+	}
+
+	width = max(width, minPrettyWidth)
+
+	var lines []string
+	line := ""
+
+	for _, word := range strings.Fields(s) {
+		for utf8.RuneCountInString(word) > width {
+			if line != "" {
+				lines, line = append(lines, line), ""
+			}
+
+			cut := len(string([]rune(word)[:width]))
+			lines, word = append(lines, word[:cut]), word[cut:]
+		}
+
+		switch {
+		case line == "":
+			line = word
+		case utf8.RuneCountInString(line)+1+utf8.RuneCountInString(word) <= width:
+			line += " " + word
+		default:
+			lines, line = append(lines, line), word
+		}
+	}
+
+	if line != "" {
+		lines = append(lines, line)
+	}
+
+	return lines
+}
+
+func isTerminal(w io.Writer) bool {
+	if _, ok := os.LookupEnv("NO_COLOR"); ok {
+		return false
+	}
+
+	f, ok := w.(*os.File)
+
+	if !ok {
+		return false
+	}
+
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
@@ -0,0 +1,87 @@
+package falta_test
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/a20r/falta"
+	"github.com/stretchr/testify/assert"
+)
+
+func prettyFixture() error {
+	cause := errors.New("connection reset")
+	inner := falta.Newf("db: query on %s failed", falta.WithCode("DB_QUERY")).New("users").
+		Annotate("read replica").
+		Wrap(cause)
+
+	return falta.NewError("api: cannot load user", falta.WithCode("USER_LOAD")).Wrap(fmt.Errorf("repo: %w", inner))
+}
+
+func TestPretty(t *testing.T) {
+	expected := `api: cannot load user
+└─ repo
+   └─ db: query on users failed
+      └─ connection reset`
+
+	assert.Equal(t, expected, falta.Pretty(prettyFixture(), falta.PrettyOptions{}))
+}
+
+func TestPretty_CodesAndAnnotations(t *testing.T) {
+	expected := `api: cannot load user [USER_LOAD]
+└─ repo
+   └─ db: query on users failed [DB_QUERY]
+      │  · read replica
+      └─ connection reset`
+
+	assert.Equal(t, expected, falta.Pretty(prettyFixture(), falta.PrettyOptions{Codes: true, Annotations: true}))
+}
+
+func TestPretty_Join(t *testing.T) {
+	err := falta.NewError("shutdown failed").Wrap(errors.Join(
+		falta.NewError("db: close failed").Annotate("pool drained"),
+		fmt.Errorf("workers: %w", errors.New("queue: close failed")),
+	))
+
+	expected := `shutdown failed
+└─ (2 errors)
+   ├─ db: close failed
+   │     · pool drained
+   └─ workers
+      └─ queue: close failed`
+
+	assert.Equal(t, expected, falta.Pretty(err, falta.PrettyOptions{Annotations: true}))
+}
+
+func TestPretty_Width(t *testing.T) {
+	err := falta.NewError("sync failed").Wrap(errors.New("the upstream closed the connection before the response was read"))
+
+	expected := `sync failed
+└─ the upstream closed
+      the connection before
+      the response was read`
+
+	assert.Equal(t, expected, falta.Pretty(err, falta.PrettyOptions{Width: 28}))
+}
+
+func TestPretty_Color(t *testing.T) {
+	as := assert.New(t)
+	err := falta.NewError("sync failed", falta.WithCode("SYNC")).Wrap(falta.NewError("queue: closed"))
+
+	as.Equal("\x1b[1msync failed\x1b[0m \x1b[33m[SYNC]\x1b[0m\n"+
+		"\x1b[2m└─ \x1b[0m\x1b[31mqueue: closed\x1b[0m",
+		falta.Pretty(err, falta.PrettyOptions{Color: falta.ColorAlways, Codes: true}))
+
+	as.NotContains(falta.Pretty(err, falta.PrettyOptions{Color: falta.ColorAuto}), "\x1b[")
+	as.NotContains(falta.Pretty(err, falta.PrettyOptions{Color: falta.ColorNever}), "\x1b[")
+}
+
+func TestFprintPretty(t *testing.T) {
+	as := assert.New(t)
+	buf := new(bytes.Buffer)
+
+	as.NoError(falta.FprintPretty(buf, prettyFixture(), falta.PrettyOptions{}))
+	as.Equal(falta.Pretty(prettyFixture(), falta.PrettyOptions{})+"\n", buf.String(),
+		"ColorAuto should not use colors when the writer is not a terminal")
+}
@@ -0,0 +1,55 @@
+package falta
+
+import (
+	"errors"
+	"sync/atomic"
+)
+
+// WithPublic declares a user-facing message alongside the internal one, for errors whose declaration mentions details
+// such as table names or hosts that must not reach end users. It uses the same syntax as the declaration and is
+// rendered from the same payload, so it can repeat the safe parts of the data.
+//
+// NOTE: A malformed public template panics where the factory is declared, not when PublicMessage first renders it.
+func WithPublic(text string) Option {
+	return func(o *options) {
+		o.public = text
+	}
+}
+
+var publicFallback atomic.Pointer[string]
+
+// SetPublicFallback sets the message PublicMessage returns for errors that do not declare a public message. It
+// defaults to "internal error".
+func SetPublicFallback(msg string) {
+	publicFallback.Store(&msg)
+}
+
+// PublicMessage returns the public message of the outermost Falta in err's chain that declares one with WithPublic,
+// or the fallback set with SetPublicFallback if none does. It never returns the internal message, so it is safe to
+// show to end users.
+func PublicMessage(err error) string {
+	for ; err != nil; err = errors.Unwrap(err) {
+		if f, ok := asLayer(err); ok {
+			if msg, ok := f.publicMessage(); ok {
+				return msg
+			}
+		}
+	}
+
+	if fallback := publicFallback.Load(); fallback != nil {
+		return *fallback
+	}
+
+	return "internal error"
+}
+
+func (f Falta) publicMessage() (string, bool) {
+	o := f.options()
+
+	if o.public == "" || o.render == nil {
+		return "", false
+	}
+
+	msg, err := o.render(o.public, "", f.payload())
+	return msg, err == nil
+}
@@ -0,0 +1,61 @@
+package falta_test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/a20r/falta"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPublicMessage(t *testing.T) {
+	as := assert.New(t)
+
+	errQuery := falta.Newf("db: query on table %s at %s failed", falta.WithPublic("the service is temporarily unavailable"))
+	errLoad := falta.NewM("api: cannot load user {{.id}} from {{.host}}",
+		falta.WithPublic("user {{.id}} could not be loaded"))
+
+	query := errQuery.New("users", "10.0.0.7:5432")
+	as.Equal("the service is temporarily unavailable", falta.PublicMessage(query))
+	as.EqualError(query, "db: query on table users at 10.0.0.7:5432 failed", "the internal message is unchanged")
+
+	load := errLoad.New(falta.M{"id": 7, "host": "db-1"}).Wrap(query)
+	as.Equal("user 7 could not be loaded", falta.PublicMessage(load), "the outermost public message wins")
+
+	wrapped := fmt.Errorf("handler: %w", falta.NewError("cache: miss").Wrap(query))
+	as.Equal("the service is temporarily unavailable", falta.PublicMessage(wrapped),
+		"layers without a public message are skipped")
+}
+
+func TestPublicMessage_Fallback(t *testing.T) {
+	as := assert.New(t)
+
+	as.Equal("internal error", falta.PublicMessage(falta.Newf("db: host %s down").New("10.0.0.7")))
+	as.Equal("internal error", falta.PublicMessage(errors.New("db: host 10.0.0.7 down")))
+	as.Equal("internal error", falta.PublicMessage(nil))
+
+	falta.SetPublicFallback("something went wrong")
+	defer falta.SetPublicFallback("internal error")
+
+	as.Equal("something went wrong", falta.PublicMessage(falta.Newf("db: host %s down").New("10.0.0.7")))
+}
+
+func TestPublicMessage_Extend(t *testing.T) {
+	as := assert.New(t)
+
+	base := falta.Newf("call to %s failed", falta.WithPublic("the upstream service failed"))
+
+	withReason := base.Extend(falta.Newf("because %s"))
+	as.Equal("the upstream service failed", falta.PublicMessage(withReason.New("billing", "timeout")),
+		"the base public message carries over")
+
+	withPublic := base.Extend(falta.Newf("after %d retries", falta.WithPublic("the upstream service failed after %[2]d retries")))
+	as.Equal("the upstream service failed after 3 retries", falta.PublicMessage(withPublic.New("billing", 3)))
+}
+
+func TestWithPublic_PanicsOnInvalidTemplate(t *testing.T) {
+	assert.Panics(t, func() {
+		falta.NewM("ok {{.a}}", falta.WithPublic("broken {{.a"))
+	})
+}
@@ -0,0 +1,282 @@
+package falta
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+	"sync/atomic"
+)
+
+// RedactMode controls how a sensitive value is masked.
+type RedactMode int
+
+const (
+	// RedactFull replaces the value with "[REDACTED]".
+	RedactFull RedactMode = iota
+
+	// RedactHash replaces the value with a short HMAC-SHA256 digest keyed with the key set by SetRedactionKey, such as
+	// "hmac:3b5e9a0c1d2f". Equal values get equal digests, so occurrences can still be correlated, and without the key
+	// the digests of guessable values, such as emails and card numbers, cannot be reversed with a dictionary.
+	RedactHash
+
+	// RedactLast4 masks all but the last four characters, such as "****4242". Values of four characters or fewer are
+	// masked entirely.
+	RedactLast4
+)
+
+// WithRedaction marks fields of the payload as sensitive. They are masked wherever the error renders its payload: its
+// message, translations, public message, and structured output such as %+v and Chain. The unmasked payload stays
+// available through Falta.Unredacted.
+//
+// For NewM, the keys are map keys. For New, they are struct field names, including those promoted from embedded
+// structs; fields can also be marked with a struct tag instead, one of `falta:"redact"`, `falta:"redact,hash"` or
+// `falta:"redact,last4"`.
+//
+// NOTE: Only template factories have named fields, so declaring a Newf or NewError factory with WithRedaction panics.
+// So does redacting a struct field that is not an exported string, or naming a field the struct does not have.
+func WithRedaction(mode RedactMode, keys ...string) Option {
+	return func(o *options) {
+		if o.redact == nil {
+			o.redact = make(map[string]RedactMode, len(keys))
+		}
+
+		for _, key := range keys {
+			o.redact[key] = mode
+		}
+	}
+}
+
+var redactionKey atomic.Pointer[[]byte]
+
+func init() {
+	key := make([]byte, 32)
+	_, _ = rand.Read(key)
+	redactionKey.Store(&key)
+}
+
+// SetRedactionKey sets the key that RedactHash digests values with. It defaults to a random key chosen when the
+// program starts, so digests only correlate within one process; set the same key everywhere to correlate them across
+// processes and restarts. Keep it as secret as the values themselves: anyone with the key can test guesses against the
+// digests.
+//
+// NOTE: It panics if the key is empty.
+func SetRedactionKey(key []byte) {
+	if len(key) == 0 {
+		panic(fmt.Errorf("falta: the redaction key cannot be empty"))
+	}
+
+	key = bytes.Clone(key)
+	redactionKey.Store(&key)
+}
+
+// Unredacted returns the payload exactly as it was passed to New, with sensitive fields unmasked. It is meant for
+// code that needs the real values, such as a retry or a support tool, and its result must be kept out of logs.
+func (f Falta) Unredacted() any {
+	if f.details == nil {
+		return nil
+	}
+
+	if f.details.raw != nil {
+		return f.details.raw
+	}
+
+	return f.details.payload
+}
+
+// redactor returns a function that copies a payload of type t with its sensitive fields masked, or nil if t has no
+// sensitive fields. It panics if a field cannot be redacted.
+func redactor(t reflect.Type, keys map[string]RedactMode) func(any) any {
+	switch {
+	case t.Kind() == reflect.Map && t.Key().Kind() == reflect.String:
+		return mapRedactor(t, keys)
+	case t.Kind() == reflect.Struct, t.Kind() == reflect.Pointer && t.Elem().Kind() == reflect.Struct:
+		return structRedactor(t, keys)
+	case len(keys) > 0:
+		panic(fmt.Errorf("falta: cannot redact fields of %s: it is not a struct or a map", t))
+	default:
+		return nil
+	}
+}
+
+func mapRedactor(t reflect.Type, keys map[string]RedactMode) func(any) any {
+	if len(keys) == 0 {
+		return nil
+	}
+
+	if t.Elem().Kind() != reflect.String && t.Elem().Kind() != reflect.Interface {
+		panic(fmt.Errorf("falta: cannot redact values of %s: masked values are strings", t))
+	}
+
+	return func(v any) any {
+		rv := reflect.ValueOf(v)
+
+		if rv.IsNil() {
+			return v
+		}
+
+		masked := reflect.MakeMapWithSize(t, rv.Len())
+		iter := rv.MapRange()
+
+		for iter.Next() {
+			value := iter.Value()
+
+			if mode, ok := keys[iter.Key().String()]; ok {
+				value = reflect.ValueOf(mask(fmt.Sprint(value.Interface()), mode)).Convert(t.Elem())
+			}
+
+			masked.SetMapIndex(iter.Key(), value)
+		}
+
+		return masked.Interface()
+	}
+}
+
+type redactedField struct {
+	index []int // the path to the field, through the structs it is embedded in
+	mode  RedactMode
+}
+
+func structRedactor(t reflect.Type, keys map[string]RedactMode) func(any) any {
+	st := t
+
+	if t.Kind() == reflect.Pointer {
+		st = t.Elem()
+	}
+
+	fields := taggedFields(st, nil)
+	tagged := make(map[string]bool, len(fields))
+
+	for _, field := range fields {
+		tagged[fmt.Sprint(field.index)] = true
+	}
+
+	for key, mode := range keys {
+		// A key that names no field would leave the value it was meant for unmasked.
+		field, ok := st.FieldByName(key)
+
+		if !ok {
+			panic(fmt.Errorf("falta: cannot redact field %s of %s: it has no such field", key, st))
+		}
+
+		if !tagged[fmt.Sprint(field.Index)] {
+			fields = append(fields, redactedField{index: field.Index, mode: mode})
+		}
+	}
+
+	for _, field := range fields {
+		checkRedactable(st, field.index)
+	}
+
+	if len(fields) == 0 {
+		return nil
+	}
+
+	return func(v any) any {
+		rv := reflect.ValueOf(v)
+
+		if t.Kind() == reflect.Pointer {
+			if rv.IsNil() {
+				return v
+			}
+
+			rv = rv.Elem()
+		}
+
+		masked := reflect.New(st).Elem()
+		masked.Set(rv)
+
+		for _, field := range fields {
+			value := masked.FieldByIndex(field.index)
+			value.SetString(mask(value.String(), field.mode))
+		}
+
+		if t.Kind() == reflect.Pointer {
+			return masked.Addr().Interface()
+		}
+
+		return masked.Interface()
+	}
+}
+
+// taggedFields returns the fields of st with a falta redaction tag, including those of the structs it embeds, whose
+// fields are promoted. index is the path to st from the payload's type.
+func taggedFields(st reflect.Type, index []int) []redactedField {
+	var fields []redactedField
+
+	for i := 0; i < st.NumField(); i++ {
+		field := st.Field(i)
+		path := append(slices.Clip(index), i)
+
+		if mode, ok := redactTag(st, field); ok {
+			fields = append(fields, redactedField{index: path, mode: mode})
+		} else if field.Anonymous && field.Type.Kind() == reflect.Struct {
+			fields = append(fields, taggedFields(field.Type, path)...)
+		}
+	}
+
+	return fields
+}
+
+// checkRedactable panics unless the field at index is an exported string field reached only through structs embedded
+// by value, since a copy of the payload could not mask it otherwise.
+func checkRedactable(st reflect.Type, index []int) {
+	current := st
+
+	for i, step := range index {
+		field := current.Field(step)
+		last := i == len(index)-1
+
+		if (last && (!field.IsExported() || field.Type.Kind() != reflect.String)) ||
+			(!last && field.Type.Kind() != reflect.Struct) {
+			panic(fmt.Errorf("falta: cannot redact field %s of %s: only exported string fields can be redacted",
+				st.FieldByIndex(index).Name, st))
+		}
+
+		current = field.Type
+	}
+}
+
+// redactTag reads the redaction mode from a field's falta struct tag, if it has one.
+func redactTag(st reflect.Type, field reflect.StructField) (RedactMode, bool) {
+	tag, ok := field.Tag.Lookup("falta")
+
+	if !ok {
+		return 0, false
+	}
+
+	switch tag {
+	case "redact", "redact,full":
+		return RedactFull, true
+	case "redact,hash":
+		return RedactHash, true
+	case "redact,last4":
+		return RedactLast4, true
+	default:
+		panic(fmt.Errorf("falta: invalid struct tag %q on field %s of %s", tag, field.Name, st))
+	}
+}
+
+func mask(value string, mode RedactMode) string {
+	switch mode {
+	case RedactHash:
+		mac := hmac.New(sha256.New, *redactionKey.Load())
+		mac.Write([]byte(value))
+		return "hmac:" + hex.EncodeToString(mac.Sum(nil)[:6])
+	case RedactLast4:
+		runes := []rune(value)
+
+		if len(runes) <= 4 {
+			return strings.Repeat("*", len(runes))
+		}
+
+		return strings.Repeat("*", len(runes)-4) + string(runes[len(runes)-4:])
+	default:
+		return "[REDACTED]"
+	}
+}
@@ -0,0 +1,191 @@
+package falta_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/a20r/falta"
+	"github.com/stretchr/testify/assert"
+)
+
+type signup struct {
+	User  string
+	Email string `falta:"redact"`
+	Token string `falta:"redact,hash"`
+	Card  string `falta:"redact,last4"`
+}
+
+func TestRedaction_StructTags(t *testing.T) {
+	as := assert.New(t)
+	falta.SetRedactionKey([]byte("falta test key"))
+	factory := falta.New[signup]("signup failed for {{.User}} <{{.Email}}> token={{.Token}} card={{.Card}}")
+
+	payload := signup{User: "ana", Email: "ana@example.com", Token: "password", Card: "4242424242424242"}
+	err := factory.New(payload)
+
+	as.EqualError(err, "signup failed for ana <[REDACTED]> token=hmac:71d2a0f88090 card=************4242")
+	as.Equal(payload, err.Unredacted(), "the privileged accessor returns the payload untouched")
+
+	frames := falta.Chain(err)
+	as.Equal(signup{User: "ana", Email: "[REDACTED]", Token: "hmac:71d2a0f88090", Card: "************4242"},
+		frames[0].Payload, "structured output sees the masked payload")
+	as.NotContains(fmt.Sprintf("%+v", err), "ana@example.com")
+	as.NotContains(fmt.Sprintf("%#v", err), "ana@example.com")
+}
+
+func TestRedaction_Pointer(t *testing.T) {
+	as := assert.New(t)
+	factory := falta.New[*signup]("signup failed for {{.User}} <{{.Email}}>")
+
+	payload := &signup{User: "ana", Email: "ana@example.com"}
+	err := factory.New(payload)
+
+	as.EqualError(err, "signup failed for ana <[REDACTED]>")
+	as.Same(payload, err.Unredacted())
+	as.Equal("ana@example.com", payload.Email, "redaction must not modify the caller's value")
+}
+
+func TestRedaction_Map(t *testing.T) {
+	as := assert.New(t)
+	factory := falta.NewM("login failed for {{.email}} from {{.ip}} with card {{.card}}",
+		falta.WithRedaction(falta.RedactFull, "email"),
+		falta.WithRedaction(falta.RedactLast4, "card"),
+	)
+
+	payload := falta.M{"email": "ana@example.com", "ip": "10.0.0.7", "card": 4242424242424242}
+	err := factory.New(payload)
+
+	as.EqualError(err, "login failed for [REDACTED] from 10.0.0.7 with card ************4242")
+	as.Equal(payload, err.Unredacted())
+	as.Equal("ana@example.com", payload["email"], "redaction must not modify the caller's map")
+}
+
+func TestRedaction_FieldNames(t *testing.T) {
+	type login struct {
+		User     string
+		Password string
+	}
+
+	factory := falta.New[login]("login failed for {{.User}} with {{.Password}}", falta.WithRedaction(falta.RedactFull, "Password"))
+
+	assert.EqualError(t, factory.New(login{User: "ana", Password: "hunter2"}), "login failed for ana with [REDACTED]")
+}
+
+func TestRedaction_Embedded(t *testing.T) {
+	type credentials struct {
+		Token  string
+		Secret string `falta:"redact"`
+	}
+
+	type login struct {
+		credentials
+		User string
+	}
+
+	type session struct {
+		*login
+	}
+
+	as := assert.New(t)
+	factory := falta.New[login]("bad token {{.Token}} or secret {{.Secret}} for {{.User}}",
+		falta.WithRedaction(falta.RedactFull, "Token"))
+
+	as.EqualError(factory.New(login{credentials: credentials{Token: "t0ken", Secret: "s3cret"}, User: "bob"}),
+		"bad token [REDACTED] or secret [REDACTED] for bob", "promoted fields are redacted like direct ones")
+
+	as.Panics(func() {
+		falta.New[session]("{{.User}}", falta.WithRedaction(falta.RedactFull, "User"))
+	}, "masking a field behind an embedded pointer would modify the caller's value")
+}
+
+func TestRedaction_Comparable(t *testing.T) {
+	as := assert.New(t)
+	factory := falta.NewM("login failed for {{.email}}", falta.WithRedaction(falta.RedactFull, "email"))
+
+	var err error = factory
+	as.NotPanics(func() { as.True(err == error(factory)) }, "redacting factories stay comparable")
+	as.Equal(1, len(map[error]bool{factory: true, err: true}))
+}
+
+func TestRedaction_PublicAndLocalized(t *testing.T) {
+	as := assert.New(t)
+	factory := falta.NewM("no account for {{.email}}",
+		falta.WithRedaction(falta.RedactFull, "email"),
+		falta.WithPublic("no account for {{.email}}"),
+		falta.WithTranslation("de", "kein Konto für {{.email}}"),
+	)
+
+	err := factory.New(falta.M{"email": "ana@example.com"})
+
+	as.Equal("no account for [REDACTED]", falta.PublicMessage(err))
+	as.EqualError(err.Localize("de"), "kein Konto für [REDACTED]")
+}
+
+func TestRedaction_Extend(t *testing.T) {
+	base := falta.NewM("login failed for {{.email}}", falta.WithRedaction(falta.RedactFull, "email"))
+	extended := base.Extend(falta.NewM("with card {{.card}}", falta.WithRedaction(falta.RedactLast4, "card")))
+
+	assert.EqualError(t, extended.New(falta.M{"email": "ana@example.com", "card": "4242424242424242"}),
+		"login failed for [REDACTED] with card ************4242")
+}
+
+func TestRedaction_HashKey(t *testing.T) {
+	as := assert.New(t)
+	factory := falta.NewM("token {{.token}}", falta.WithRedaction(falta.RedactHash, "token"))
+
+	falta.SetRedactionKey([]byte("one key"))
+	first := factory.New(falta.M{"token": "password"}).Error()
+	as.Equal(first, factory.New(falta.M{"token": "password"}).Error(), "equal values get equal digests")
+	as.NotEqual(first, factory.New(falta.M{"token": "passw0rd"}).Error())
+
+	falta.SetRedactionKey([]byte("another key"))
+	as.NotEqual(first, factory.New(falta.M{"token": "password"}).Error(), "digests depend on the key")
+
+	as.Panics(func() { falta.SetRedactionKey(nil) })
+}
+
+func TestRedaction_Short(t *testing.T) {
+	factory := falta.NewM("pin {{.pin}}", falta.WithRedaction(falta.RedactLast4, "pin"))
+
+	assert.EqualError(t, factory.New(falta.M{"pin": "1234"}), "pin ****", "short values are masked entirely")
+}
+
+func TestRedaction_Panics(t *testing.T) {
+	as := assert.New(t)
+
+	as.Panics(func() {
+		falta.Newf("login failed for %s", falta.WithRedaction(falta.RedactFull, "email"))
+	}, "printf factories have no named fields")
+
+	as.Panics(func() {
+		falta.NewError("login failed", falta.WithRedaction(falta.RedactFull, "email"))
+	})
+
+	as.Panics(func() {
+		type account struct {
+			Balance int `falta:"redact"`
+		}
+
+		falta.New[account]("balance {{.Balance}}")
+	}, "only string fields can be redacted")
+
+	as.Panics(func() {
+		type account struct {
+			Owner string `falta:"redakt"`
+		}
+
+		falta.New[account]("owner {{.Owner}}")
+	}, "a misspelled tag should not silently leak the value")
+
+	as.Panics(func() {
+		falta.New[signup]("signup failed for {{.Email}}", falta.WithRedaction(falta.RedactFull, "email"))
+	}, "a key that names no field should not silently leak the value")
+
+	as.Panics(func() {
+		falta.New[int]("{{.}}", falta.WithRedaction(falta.RedactFull, "x"))
+	})
+
+	as.Panics(func() {
+		falta.New[map[string]int]("{{.pin}}", falta.WithRedaction(falta.RedactFull, "pin"))
+	}, "a masked value has to fit in the map")
+}
@@ -0,0 +1,145 @@
+package falta
+
+import (
+	"fmt"
+	"log/slog"
+	"sort"
+	"sync"
+	"sync/atomic"
+)
+
+// WithRegistry registers the factory in the runtime registry under the namespace provided, such as the name of the
+// package that declares it, so it can be found again with Lookup, LookupFormat and All. A factory made by Extend is
+// not registered.
+//
+// NOTE: Registering a second factory with the same code or declaration is a mistake that SetDuplicatePolicy decides
+// how to handle. By default, it panics when the second factory is declared.
+func WithRegistry(namespace string) Option {
+	return func(o *options) {
+		o.registry = &namespace
+	}
+}
+
+// Registered describes a factory in the runtime registry.
+type Registered struct {
+	// Namespace is the namespace the factory was registered under.
+	Namespace string
+
+	// Format is the factory's declaration string.
+	Format string
+
+	// Code is the code the factory was declared with, if any.
+	Code string
+
+	// Factory is the factory itself: a Factory, such as ExtendableFactory[any] for Newf, or a Falta for NewError.
+	// Like the factories themselves, it matches the errors the factory builds with errors.Is.
+	Factory error
+}
+
+// DuplicatePolicy is what happens when a factory is registered with a code or declaration that is already taken.
+type DuplicatePolicy int
+
+const (
+	// DuplicatesPanic panics when the duplicate is declared. This is the default.
+	DuplicatesPanic DuplicatePolicy = iota
+
+	// DuplicatesWarn logs a warning with the default slog logger, and keeps the factory that was registered first.
+	DuplicatesWarn
+)
+
+var duplicatePolicy atomic.Int64
+
+// SetDuplicatePolicy sets what happens when a factory is registered with a code or declaration that is already taken.
+func SetDuplicatePolicy(policy DuplicatePolicy) {
+	duplicatePolicy.Store(int64(policy))
+}
+
+// registry holds the factories declared with WithRegistry.
+var registry = &factoryRegistry{byCode: make(map[string]Registered), byFormat: make(map[string]Registered)}
+
+type factoryRegistry struct {
+	mu       sync.RWMutex
+	byCode   map[string]Registered
+	byFormat map[string]Registered
+}
+
+func (r *factoryRegistry) add(entry Registered) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := r.duplicate(entry); err != nil {
+		if DuplicatePolicy(duplicatePolicy.Load()) == DuplicatesWarn {
+			slog.Warn(err.Error(), "namespace", entry.Namespace, "format", entry.Format, "code", entry.Code)
+			return
+		}
+
+		panic(err)
+	}
+
+	r.byFormat[entry.Format] = entry
+
+	if entry.Code != "" {
+		r.byCode[entry.Code] = entry
+	}
+}
+
+func (r *factoryRegistry) duplicate(entry Registered) error {
+	if existing, ok := r.byFormat[entry.Format]; ok {
+		return fmt.Errorf("falta: factory %q is already registered in namespace %q", entry.Format, existing.Namespace)
+	}
+
+	if existing, ok := r.byCode[entry.Code]; ok && entry.Code != "" {
+		return fmt.Errorf("falta: code %q is already registered for factory %q", entry.Code, existing.Format)
+	}
+
+	return nil
+}
+
+// register adds a factory that was just declared to the registry, if its options ask for it.
+func register(opts *options, format string, factory error) {
+	if opts.registry == nil {
+		return
+	}
+
+	registry.add(Registered{Namespace: *opts.registry, Format: format, Code: opts.code, Factory: factory})
+}
+
+// Lookup returns the registered factory declared with the code provided.
+func Lookup(code string) (Registered, bool) {
+	registry.mu.RLock()
+	defer registry.mu.RUnlock()
+
+	entry, ok := registry.byCode[code]
+	return entry, ok
+}
+
+// LookupFormat returns the registered factory with the declaration string provided.
+func LookupFormat(format string) (Registered, bool) {
+	registry.mu.RLock()
+	defer registry.mu.RUnlock()
+
+	entry, ok := registry.byFormat[format]
+	return entry, ok
+}
+
+// All returns every registered factory, sorted by namespace and then by declaration.
+func All() []Registered {
+	registry.mu.RLock()
+	entries := make([]Registered, 0, len(registry.byFormat))
+
+	for _, entry := range registry.byFormat {
+		entries = append(entries, entry)
+	}
+
+	registry.mu.RUnlock()
+
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].Namespace != entries[j].Namespace {
+			return entries[i].Namespace < entries[j].Namespace
+		}
+
+		return entries[i].Format < entries[j].Format
+	})
+
+	return entries
+}
@@ -0,0 +1,105 @@
+package falta_test
+
+import (
+	"bytes"
+	"errors"
+	"log/slog"
+	"sort"
+	"testing"
+
+	"github.com/a20r/falta"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	errRegistryNotFound = falta.Newf("registry test: no user with id %d",
+		falta.WithRegistry("userstore"), falta.WithCode("REGISTRY_TEST_NOT_FOUND"))
+	errRegistryClosed = falta.NewError("registry test: store closed", falta.WithRegistry("userstore"))
+	errRegistryQuota  = falta.NewM("registry test: quota for {{.user}} exceeded",
+		falta.WithRegistry("billing"), falta.WithCode("REGISTRY_TEST_QUOTA"))
+)
+
+func TestLookup(t *testing.T) {
+	as := assert.New(t)
+
+	entry, ok := falta.Lookup("REGISTRY_TEST_NOT_FOUND")
+	as.True(ok)
+	as.Equal("userstore", entry.Namespace)
+	as.Equal("registry test: no user with id %d", entry.Format)
+	as.Equal("REGISTRY_TEST_NOT_FOUND", entry.Code)
+	as.ErrorIs(errRegistryNotFound.New(42), entry.Factory, "the registered factory keeps its identity")
+
+	factory, ok := entry.Factory.(falta.ExtendableFactory[any])
+	as.True(ok)
+	as.EqualError(factory.New(7), "registry test: no user with id 7")
+
+	entry, ok = falta.LookupFormat("registry test: store closed")
+	as.True(ok)
+	as.Equal(errRegistryClosed, entry.Factory)
+	as.Empty(entry.Code)
+
+	_, ok = falta.Lookup("REGISTRY_TEST_MISSING")
+	as.False(ok)
+	_, ok = falta.LookupFormat("registry test: missing")
+	as.False(ok)
+}
+
+func TestAll(t *testing.T) {
+	as := assert.New(t)
+
+	entries := falta.All()
+	var formats []string
+
+	for _, entry := range entries {
+		formats = append(formats, entry.Namespace+": "+entry.Format)
+	}
+
+	as.Subset(formats, []string{
+		"billing: registry test: quota for {{.user}} exceeded",
+		"userstore: registry test: no user with id %d",
+		"userstore: registry test: store closed",
+	})
+	as.True(sort.SliceIsSorted(entries, func(i, j int) bool {
+		if entries[i].Namespace != entries[j].Namespace {
+			return entries[i].Namespace < entries[j].Namespace
+		}
+
+		return entries[i].Format < entries[j].Format
+	}), "entries are sorted by namespace, then declaration")
+
+	falta.NewError("registry test: unregistered")
+	_, ok := falta.LookupFormat("registry test: unregistered")
+	as.False(ok, "registering is opt-in")
+
+	errRegistryQuota.Extend(falta.NewM("on plan {{.plan}}"))
+	_, ok = falta.LookupFormat("registry test: quota for {{.user}} exceeded on plan {{.plan}}")
+	as.False(ok, "extended factories are not registered")
+}
+
+func TestRegistry_Duplicates(t *testing.T) {
+	as := assert.New(t)
+
+	as.Panics(func() {
+		falta.NewError("registry test: store closed", falta.WithRegistry("other"))
+	}, "duplicate declarations panic by default")
+
+	as.Panics(func() {
+		falta.NewError("registry test: something else", falta.WithRegistry("other"), falta.WithCode("REGISTRY_TEST_QUOTA"))
+	}, "so do duplicate codes")
+
+	buf := new(bytes.Buffer)
+	defer slog.SetDefault(slog.Default())
+	slog.SetDefault(slog.New(slog.NewTextHandler(buf, nil)))
+
+	falta.SetDuplicatePolicy(falta.DuplicatesWarn)
+	defer falta.SetDuplicatePolicy(falta.DuplicatesPanic)
+
+	as.NotPanics(func() {
+		falta.Newf("registry test: no user with id %d", falta.WithRegistry("other"))
+	})
+	as.Contains(buf.String(), `level=WARN msg="falta: factory \"registry test: no user with id %d\" is already registered in namespace \"userstore\""`)
+
+	entry, _ := falta.LookupFormat("registry test: no user with id %d")
+	as.Equal("userstore", entry.Namespace, "the first factory is kept")
+	as.True(errors.Is(errRegistryNotFound.New(1), entry.Factory))
+}
@@ -0,0 +1,163 @@
+package falta
+
+import (
+	"context"
+	"errors"
+	"net"
+	"time"
+)
+
+// retryClass is how a factory classifies its errors for retrying.
+type retryClass int
+
+const (
+	retryUnset retryClass = iota
+	retryRetryable
+	retryPermanent
+)
+
+// WithRetryable declares the factory's errors as temporary: the operation that failed may succeed if it is tried
+// again. A backoff greater than zero is a hint for how long to wait before the next attempt, read back with
+// RetryDelay.
+func WithRetryable(backoff time.Duration) Option {
+	return func(o *options) {
+		o.retry = retryRetryable
+		o.backoff = backoff
+	}
+}
+
+// WithPermanent declares the factory's errors as permanent: trying the operation again will fail the same way. It is
+// useful to stop retries for an error that wraps a temporary one.
+func WithPermanent() Option {
+	return func(o *options) {
+		o.retry = retryPermanent
+		o.backoff = 0
+	}
+}
+
+// RetryAfter marks this error as retryable after the duration provided, such as the delay from a Retry-After header.
+// It overrides the classification of the factory.
+func (f Falta) RetryAfter(d time.Duration) Falta {
+	f.details = f.details.clone()
+	f.details.retryAfter = d
+	return f
+}
+
+// classification returns how this layer of a chain is classified, and the delay it asks for.
+func (f Falta) classification() (retryClass, time.Duration) {
+	if f.details != nil && f.details.retryAfter > 0 {
+		return retryRetryable, f.details.retryAfter
+	}
+
+	return f.options().retry, f.options().backoff
+}
+
+// IsRetryable reports whether the operation that returned err is worth trying again. It walks err's chain from the
+// outermost error inwards and the first explicit classification wins: a Falta declared with WithRetryable or
+// WithPermanent or given a RetryAfter, context.DeadlineExceeded (retryable), context.Canceled (permanent), or a
+// net.Error that reports a timeout (retryable). Errors with no classification are not retryable.
+func IsRetryable(err error) bool {
+	class, _ := classify(err)
+	return class == retryRetryable
+}
+
+// RetryDelay returns how long to wait before retrying err, as asked for by the classification IsRetryable found, or
+// zero if err is not retryable or did not ask for a delay.
+func RetryDelay(err error) time.Duration {
+	class, delay := classify(err)
+
+	if class != retryRetryable {
+		return 0
+	}
+
+	return delay
+}
+
+// classify returns the first explicit classification in err's chain, searching depth-first from the outermost error.
+func classify(err error) (retryClass, time.Duration) {
+	if err == nil {
+		return retryUnset, 0
+	}
+
+	if f, ok := asLayer(err); ok {
+		if class, delay := f.classification(); class != retryUnset {
+			return class, delay
+		}
+	}
+
+	switch err { //nolint:errorlint // each layer of the chain is classified on its own
+	case context.DeadlineExceeded:
+		return retryRetryable, 0
+	case context.Canceled:
+		return retryPermanent, 0
+	}
+
+	if netErr, ok := err.(net.Error); ok && netErr.Timeout() { //nolint:errorlint // as above
+		return retryRetryable, 0
+	}
+
+	for _, cause := range causes(err) {
+		if class, delay := classify(cause); class != retryUnset {
+			return class, delay
+		}
+	}
+
+	return retryUnset, 0
+}
+
+// DefaultRetryDelay is the delay before the second attempt of a RetryPolicy that sets none.
+const DefaultRetryDelay = 100 * time.Millisecond
+
+// RetryPolicy controls how Retry spaces out its attempts.
+type RetryPolicy struct {
+	// Attempts is the most times the operation is tried. Zero or less means it is tried until it succeeds, fails with
+	// an error that is not retryable, or the context is done.
+	Attempts int
+
+	// Delay is the wait before the second attempt. It doubles after each attempt after that. Zero or less means
+	// DefaultRetryDelay, so that the zero RetryPolicy backs off instead of calling the operation in a tight loop.
+	Delay time.Duration
+
+	// MaxDelay caps the doubling Delay. Zero or less means no cap.
+	MaxDelay time.Duration
+}
+
+// Retry calls fn until it succeeds, returns an error that IsRetryable rejects, or the policy runs out of attempts, and
+// returns fn's last error. Between attempts, it waits for the error's RetryDelay if it has one, and for the policy's
+// delay otherwise. If ctx is done while waiting, Retry returns fn's last error joined with ctx's error.
+func Retry(ctx context.Context, policy RetryPolicy, fn func(ctx context.Context) error) error {
+	delay := policy.Delay
+
+	if delay <= 0 {
+		delay = DefaultRetryDelay
+	}
+
+	for attempt := 1; ; attempt++ {
+		err := fn(ctx)
+
+		if err == nil || !IsRetryable(err) || (policy.Attempts > 0 && attempt >= policy.Attempts) {
+			return err
+		}
+
+		wait := delay
+
+		if hint := RetryDelay(err); hint > 0 {
+			wait = hint
+		}
+
+		timer := time.NewTimer(wait)
+
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return errors.Join(err, ctx.Err())
+		case <-timer.C:
+		}
+
+		delay *= 2
+
+		if policy.MaxDelay > 0 {
+			delay = min(delay, policy.MaxDelay)
+		}
+	}
+}
@@ -0,0 +1,136 @@
+package falta_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/a20r/falta"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsRetryable(t *testing.T) {
+	as := assert.New(t)
+
+	errUnavailable := falta.Newf("store: %s is unavailable", falta.WithRetryable(time.Second))
+	errInvalid := falta.NewError("api: invalid request", falta.WithPermanent())
+	errLoad := falta.NewError("api: cannot load user")
+
+	as.True(falta.IsRetryable(errUnavailable.New("db1")))
+	as.Equal(time.Second, falta.RetryDelay(errUnavailable.New("db1")))
+	as.False(falta.IsRetryable(errInvalid.Wrap(errUnavailable.New("db1"))), "the outermost classification wins")
+	as.Zero(falta.RetryDelay(errInvalid.Wrap(errUnavailable.New("db1"))))
+	as.True(falta.IsRetryable(fmt.Errorf("handler: %w", errLoad.Wrap(errUnavailable.New("db1")))),
+		"layers without a classification are skipped")
+	as.False(falta.IsRetryable(errLoad))
+	as.False(falta.IsRetryable(errors.New("plain")))
+	as.False(falta.IsRetryable(nil))
+}
+
+func TestIsRetryable_Standard(t *testing.T) {
+	as := assert.New(t)
+	errLoad := falta.NewError("api: cannot load user")
+
+	as.True(falta.IsRetryable(errLoad.Wrap(context.DeadlineExceeded)))
+	as.False(falta.IsRetryable(errLoad.Wrap(context.Canceled)))
+	as.True(falta.IsRetryable(errLoad.Wrap(&net.DNSError{Err: "timeout", IsTimeout: true})))
+	as.False(falta.IsRetryable(errLoad.Wrap(&net.DNSError{Err: "no such host", IsNotFound: true})))
+	as.False(falta.IsRetryable(errors.Join(context.Canceled, context.DeadlineExceeded)),
+		"the first classification found wins")
+	as.True(falta.IsRetryable(errors.Join(errors.New("plain"), context.DeadlineExceeded)))
+}
+
+func TestRetryAfter(t *testing.T) {
+	as := assert.New(t)
+	errInvalid := falta.NewError("api: rate limited", falta.WithPermanent())
+
+	err := errInvalid.RetryAfter(3 * time.Second)
+
+	as.True(falta.IsRetryable(err), "the instance overrides its factory")
+	as.Equal(3*time.Second, falta.RetryDelay(err))
+	as.False(falta.IsRetryable(errInvalid), "RetryAfter must not mutate the error")
+}
+
+func TestRetryable_Extend(t *testing.T) {
+	as := assert.New(t)
+	base := falta.Newf("store: %s failed", falta.WithRetryable(0))
+
+	as.True(falta.IsRetryable(base.Extend(falta.Newf("with %s")).New("db1", "timeout")))
+	as.False(falta.IsRetryable(base.Extend(falta.Newf("with %s", falta.WithPermanent())).New("db1", "bad query")),
+		"the extending factory's classification is more specific")
+}
+
+func TestRetry(t *testing.T) {
+	as := assert.New(t)
+	errUnavailable := falta.NewError("store: unavailable", falta.WithRetryable(0))
+	errInvalid := falta.NewError("store: invalid key", falta.WithPermanent())
+	policy := falta.RetryPolicy{Attempts: 3, Delay: time.Millisecond}
+
+	calls := 0
+	err := falta.Retry(context.Background(), policy, func(context.Context) error {
+		calls++
+
+		if calls < 3 {
+			return errUnavailable
+		}
+
+		return nil
+	})
+	as.NoError(err)
+	as.Equal(3, calls)
+
+	calls = 0
+	err = falta.Retry(context.Background(), policy, func(context.Context) error {
+		calls++
+		return errUnavailable
+	})
+	as.ErrorIs(err, errUnavailable)
+	as.Equal(3, calls, "the policy's attempts are a limit")
+
+	calls = 0
+	err = falta.Retry(context.Background(), policy, func(context.Context) error {
+		calls++
+		return errInvalid
+	})
+	as.ErrorIs(err, errInvalid)
+	as.Equal(1, calls, "permanent errors are not retried")
+}
+
+func TestRetry_Context(t *testing.T) {
+	as := assert.New(t)
+	errUnavailable := falta.NewError("store: unavailable")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	calls := 0
+
+	err := falta.Retry(ctx, falta.RetryPolicy{}, func(context.Context) error {
+		calls++
+		cancel()
+		return errUnavailable.RetryAfter(time.Hour)
+	})
+
+	as.ErrorIs(err, errUnavailable)
+	as.ErrorIs(err, context.Canceled, "waiting stops when the context is done")
+	as.Equal(1, calls)
+}
+
+func TestRetry_DefaultDelay(t *testing.T) {
+	as := assert.New(t)
+	errUnavailable := falta.NewError("store: unavailable", falta.WithRetryable(0))
+
+	ctx, cancel := context.WithTimeout(context.Background(), falta.DefaultRetryDelay/2)
+	defer cancel()
+
+	calls := 0
+
+	err := falta.Retry(ctx, falta.RetryPolicy{}, func(context.Context) error {
+		calls++
+		return errUnavailable
+	})
+
+	as.ErrorIs(err, context.DeadlineExceeded)
+	as.Equal(1, calls, "the zero policy waits before trying again")
+}
@@ -0,0 +1,190 @@
+package falta
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"text/template"
+	"text/template/parse"
+	"unicode/utf8"
+)
+
+// SanitizeLimits bounds the size of sanitized messages. A limit of zero or less means no limit.
+type SanitizeLimits struct {
+	// MaxValue is the most runes a single interpolated value may render to.
+	MaxValue int
+
+	// MaxMessage is the most runes the whole message, as returned by Error, may have.
+	MaxMessage int
+}
+
+// truncationMarker ends a value or message that was cut short by a SanitizeLimits limit.
+const truncationMarker = "…"
+
+// WithSanitizing makes the factory sanitize the values it interpolates, so that the messages it builds are safe to
+// write to line-oriented logs. Terminal escape sequences are stripped, control characters such as newlines are
+// escaped as \n, \x1b and so on, and each value and the whole message are truncated to the limits provided, ending
+// with "…".
+//
+// Sanitizing applies to the payload wherever it is rendered, to the arguments of Annotatef, and to attributes rendered
+// into the message. Values are sanitized as they are rendered; the payload kept on the error is left as it was.
+func WithSanitizing(limits SanitizeLimits) Option {
+	return func(o *options) {
+		o.sanitize = &limits
+	}
+}
+
+// sanitizeFunc is the name of the template function that sanitizes the output of every action in a sanitizing
+// template factory.
+const sanitizeFunc = "falta_sanitize"
+
+// terminalEscapes matches ANSI CSI sequences (colors, cursor movement), OSC sequences (window titles, hyperlinks) and
+// the remaining two-character escapes.
+var terminalEscapes = regexp.MustCompile(`\x1b\[[0-?]*[ -/]*[@-~]|\x1b\][^\x07\x1b]*(?:\x07|\x1b\\)|\x1b[@-Z\\-_]`)
+
+// sanitizeValue returns s made safe for a single log line and cut down to limit runes.
+func sanitizeValue(s string, limit int) string {
+	s = terminalEscapes.ReplaceAllString(s, "")
+	builder := new(strings.Builder)
+
+	for _, r := range s {
+		switch {
+		case r == '\n':
+			builder.WriteString(`\n`)
+		case r == '\r':
+			builder.WriteString(`\r`)
+		case r == '\t':
+			builder.WriteString(`\t`)
+		case r < 0x20 || r == 0x7f:
+			fmt.Fprintf(builder, `\x%02x`, r)
+		case r >= 0x80 && r <= 0x9f, r == '\u2028', r == '\u2029':
+			fmt.Fprintf(builder, `\u%04x`, r)
+		default:
+			builder.WriteRune(r)
+		}
+	}
+
+	return truncate(builder.String(), limit)
+}
+
+// truncate cuts s down to limit runes, including the truncation marker. A limit of zero or less means no limit.
+func truncate(s string, limit int) string {
+	if limit <= 0 || utf8.RuneCountInString(s) <= limit {
+		return s
+	}
+
+	runes := []rune(s)
+	return string(runes[:max(limit-utf8.RuneCountInString(truncationMarker), 0)]) + truncationMarker
+}
+
+// sanitizeString sanitizes a value rendered by a factory declared with WithSanitizing, and returns it unchanged
+// otherwise.
+func (o *options) sanitizeString(s string) string {
+	if o.sanitize == nil {
+		return s
+	}
+
+	return sanitizeValue(s, o.sanitize.MaxValue)
+}
+
+// truncateMessage cuts a whole message down to the factory's MaxMessage limit, if it sanitizes.
+func (o *options) truncateMessage(msg string) string {
+	if o.sanitize == nil {
+		return msg
+	}
+
+	return truncate(msg, o.sanitize.MaxMessage)
+}
+
+// sanitizeArgs wraps printf arguments so that each is sanitized after fmt formats it, if the factory sanitizes.
+func (o *options) sanitizeArgs(args []any) []any {
+	if o.sanitize == nil {
+		return args
+	}
+
+	wrapped := make([]any, len(args))
+
+	for i, arg := range args {
+		if err, ok := arg.(error); ok {
+			wrapped[i] = sanitizedError{sanitizedArg: sanitizedArg{arg: err, opts: o}, err: err}
+		} else {
+			wrapped[i] = sanitizedArg{arg: arg, opts: o}
+		}
+	}
+
+	return wrapped
+}
+
+// sanitizedArg formats its argument with the verb and flags it is given, then sanitizes the result.
+type sanitizedArg struct {
+	arg  any
+	opts *options
+}
+
+func (a sanitizedArg) Format(s fmt.State, verb rune) {
+	fmt.Fprint(s, a.opts.sanitizeString(fmt.Sprintf(fmt.FormatString(s, verb), a.arg)))
+}
+
+// sanitizedError is a sanitizedArg for errors, so that fmt still accepts them for %w.
+type sanitizedError struct {
+	sanitizedArg
+	err error
+}
+
+func (e sanitizedError) Error() string {
+	return e.err.Error()
+}
+
+func (e sanitizedError) Unwrap() error {
+	return e.err
+}
+
+// sanitizeTemplate rewrites every action in tmpl that produces output so that its result is piped through the
+// sanitize function, the same way html/template inserts its escapers.
+func sanitizeTemplate(tmpl *template.Template) {
+	for _, t := range tmpl.Templates() {
+		if t.Tree != nil {
+			sanitizeList(t.Tree.Root)
+		}
+	}
+}
+
+func sanitizeList(list *parse.ListNode) {
+	if list == nil {
+		return
+	}
+
+	for _, node := range list.Nodes {
+		switch n := node.(type) {
+		case *parse.ActionNode:
+			if len(n.Pipe.Decl) == 0 {
+				n.Pipe.Cmds = append(n.Pipe.Cmds, &parse.CommandNode{
+					NodeType: parse.NodeCommand,
+					Pos:      n.Pos,
+					Args:     []parse.Node{parse.NewIdentifier(sanitizeFunc).SetTree(nil).SetPos(n.Pos)},
+				})
+			}
+		case *parse.IfNode:
+			sanitizeList(n.List)
+			sanitizeList(n.ElseList)
+		case *parse.RangeNode:
+			sanitizeList(n.List)
+			sanitizeList(n.ElseList)
+		case *parse.WithNode:
+			sanitizeList(n.List)
+			sanitizeList(n.ElseList)
+		}
+	}
+}
+
+// sanitizeTemplateFunc returns the template function that sanitizes action output for a factory.
+func (o *options) sanitizeTemplateFunc() func(v any) string {
+	return func(v any) string {
+		if v == nil {
+			// Match what text/template prints for a missing map key.
+			return "<no value>"
+		}
+
+		return o.sanitizeString(fmt.Sprint(v))
+	}
+}
@@ -0,0 +1,84 @@
+package falta_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/a20r/falta"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSanitizing_Fmt(t *testing.T) {
+	as := assert.New(t)
+	factory := falta.Newf("http: bad header %q: %s", falta.WithSanitizing(falta.SanitizeLimits{}))
+
+	err := factory.New("X-Name", "evil\nlevel=error msg=forged\r\t\x00")
+	as.EqualError(err, `http: bad header "X-Name": evil\nlevel=error msg=forged\r\t\x00`)
+	as.Equal([]any{"X-Name", "evil\nlevel=error msg=forged\r\t\x00"}, falta.Chain(err)[0].Payload,
+		"the payload is kept as it was")
+
+	as.EqualError(factory.New("X-Name", "\x1b[31mred\x1b[0m \x1b]0;title\x07done"), `http: bad header "X-Name": red done`,
+		"terminal escape sequences are stripped")
+	as.EqualError(factory.New("X-Name", "line\u2028separator\u0085"), `http: bad header "X-Name": line\u2028separator\u0085`)
+}
+
+func TestSanitizing_Truncation(t *testing.T) {
+	as := assert.New(t)
+	factory := falta.Newf("upload: bad name %s in %s",
+		falta.WithSanitizing(falta.SanitizeLimits{MaxValue: 8, MaxMessage: 40}))
+
+	as.EqualError(factory.New("abcdefghijkl", "dir"), "upload: bad name abcdefg… in dir")
+	as.EqualError(factory.New("ab", "dir").Annotate(strings.Repeat("x", 40)),
+		"upload: bad name ab in dir: xxxxxxxxxxx…")
+	as.Len([]rune(factory.New("ab", "dir").Annotate(strings.Repeat("x", 40)).Error()), 40)
+	as.EqualError(factory.New("ab\n\n\n\n", "dir"), `upload: bad name ab\n\n\… in dir`,
+		"values are truncated after they are escaped")
+}
+
+func TestSanitizing_Template(t *testing.T) {
+	type request struct {
+		User  string
+		Paths []string
+	}
+
+	as := assert.New(t)
+	factory := falta.New[request]("sync: {{.User}}{{if .Paths}} cannot write{{range .Paths}} {{.}}{{end}}{{end}}",
+		falta.WithSanitizing(falta.SanitizeLimits{MaxValue: 12}))
+
+	err := factory.New(request{User: "ana\x1b[2J", Paths: []string{"/tmp/a\nb", "/tmp/a-very-long-path"}})
+	as.EqualError(err, `sync: ana cannot write /tmp/a\nb /tmp/a-very…`)
+
+	mapFactory := falta.NewM("login failed for {{.user}} from {{.ip}}", falta.WithSanitizing(falta.SanitizeLimits{}))
+	as.EqualError(mapFactory.New(falta.M{"user": "ana\r\nroot"}), `login failed for ana\r\nroot from <no value>`,
+		"a missing key renders as it does without sanitizing")
+}
+
+func TestSanitizing_WrapVerb(t *testing.T) {
+	as := assert.New(t)
+	factory := falta.Newf("load %s: %w", falta.WithSanitizing(falta.SanitizeLimits{}))
+
+	cause := errors.New("disk\nfull")
+	err := factory.New("config", cause)
+
+	as.EqualError(err, `load config: disk\nfull`, "errors can still be formatted with %w")
+	as.ErrorIs(err, factory)
+}
+
+func TestSanitizing_AnnotationsAndAttrs(t *testing.T) {
+	as := assert.New(t)
+	factory := falta.NewError("auth: rejected",
+		falta.WithSanitizing(falta.SanitizeLimits{}),
+		falta.WithAttrRendering(falta.AttrsInline),
+	)
+
+	err := factory.Annotatef("user %s", "ana\nlevel=info").With("agent", "curl\x1b[1m")
+	as.EqualError(err, `auth: rejected: user ana\nlevel=info agent=curl`)
+	as.Equal([]string{"user ana\\nlevel=info"}, falta.Annotations(err))
+}
+
+func TestSanitizing_Off(t *testing.T) {
+	factory := falta.Newf("http: bad header %s")
+
+	assert.EqualError(t, factory.New("a\nb"), "http: bad header a\nb", "sanitizing is opt-in")
+}
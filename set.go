@@ -0,0 +1,37 @@
+package falta
+
+import "errors"
+
+// ErrorSet is a declared set of the errors an API may return, made with Set. Attach it to a function with a
+// directive comment naming the set, and the errset analyzer in github.com/a20r/falta/analysis checks that the
+// function only returns errors from the set, and that Match switches over its results handle every member:
+//
+//	var LoadErrors = falta.Set(ErrUserNotFound, ErrStoreClosed)
+//
+//	//falta:returns LoadErrors
+//	func Load(id int) (User, error) { ... }
+type ErrorSet struct {
+	members []error
+}
+
+// Set declares a set of errors, usually factories. Declare it as a package-level variable so the analyzer can read
+// its members.
+func Set(members ...error) ErrorSet {
+	return ErrorSet{members: members}
+}
+
+// Members returns the members of the set, in the order they were declared.
+func (s ErrorSet) Members() []error {
+	return append([]error(nil), s.members...)
+}
+
+// Contains reports whether err matches a member of the set with errors.Is.
+func (s ErrorSet) Contains(err error) bool {
+	for _, member := range s.members {
+		if errors.Is(err, member) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,44 @@
+package falta_test
+
+import (
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/a20r/falta"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	errSetNotFound = falta.Newf("set test: no user with id %d")
+	errSetClosed   = falta.NewError("set test: store closed")
+	errSetOther    = falta.NewError("set test: something else")
+
+	setLoadErrors = falta.Set(errSetNotFound, errSetClosed)
+)
+
+func TestSetMembers(t *testing.T) {
+	as := assert.New(t)
+
+	members := setLoadErrors.Members()
+	as.Len(members, 2)
+	as.ErrorIs(members[0], errSetNotFound)
+	as.ErrorIs(members[1], errSetClosed)
+
+	members[0] = io.EOF
+	as.ErrorIs(setLoadErrors.Members()[0], errSetNotFound)
+}
+
+func TestSetContains(t *testing.T) {
+	as := assert.New(t)
+
+	as.True(setLoadErrors.Contains(errSetNotFound.New(42)))
+	as.True(setLoadErrors.Contains(errSetClosed))
+	as.True(setLoadErrors.Contains(errSetClosed.Wrap(io.EOF)))
+	as.True(setLoadErrors.Contains(errors.Join(io.EOF, errSetNotFound.New(1))))
+
+	as.False(setLoadErrors.Contains(errSetOther))
+	as.False(setLoadErrors.Contains(io.EOF))
+	as.False(setLoadErrors.Contains(nil))
+	as.False(falta.Set().Contains(errSetClosed))
+}
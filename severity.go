@@ -0,0 +1,104 @@
+package falta
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+)
+
+// Level is how severe an error is, from LevelDebug to LevelCritical. The zero Level means no severity was declared.
+type Level int
+
+const (
+	// LevelDebug is for errors that are only interesting while debugging, such as a cache miss.
+	LevelDebug Level = iota + 1
+
+	// LevelInfo is for expected errors that are worth recording, such as a failed login.
+	LevelInfo
+
+	// LevelWarn is for errors that may need attention if they keep happening.
+	LevelWarn
+
+	// LevelError is for errors that need attention.
+	LevelError
+
+	// LevelCritical is for errors that need attention now, such as those that should page someone.
+	LevelCritical
+)
+
+// String returns the level's name in lower case, such as "warn".
+func (l Level) String() string {
+	switch l {
+	case 0:
+		return "unset"
+	case LevelDebug:
+		return "debug"
+	case LevelInfo:
+		return "info"
+	case LevelWarn:
+		return "warn"
+	case LevelError:
+		return "error"
+	case LevelCritical:
+		return "critical"
+	default:
+		return fmt.Sprintf("Level(%d)", int(l))
+	}
+}
+
+// SlogLevel returns the slog level to log an error of this severity at. LevelCritical maps to four above
+// slog.LevelError, following slog's spacing between levels, and the zero Level maps to slog.LevelError.
+func (l Level) SlogLevel() slog.Level {
+	switch l {
+	case LevelDebug:
+		return slog.LevelDebug
+	case LevelInfo:
+		return slog.LevelInfo
+	case LevelWarn:
+		return slog.LevelWarn
+	case LevelCritical:
+		return slog.LevelError + 4
+	default:
+		return slog.LevelError
+	}
+}
+
+// WithSeverity declares the severity of the factory's errors.
+func WithSeverity(level Level) Option {
+	return func(o *options) {
+		o.severity = level
+	}
+}
+
+// WithSeverity overrides the severity the error's factory declared.
+func (f Falta) WithSeverity(level Level) Falta {
+	f.details = f.details.clone()
+	f.details.severity = level
+	return f
+}
+
+// severity returns the severity of this layer of a chain.
+func (f Falta) severity() Level {
+	if f.details != nil && f.details.severity != 0 {
+		return f.details.severity
+	}
+
+	return f.options().severity
+}
+
+// Severity returns the highest severity of any Falta in err's chain, or the zero Level if none has one.
+func Severity(err error) Level {
+	var highest Level
+
+	for _, frame := range Chain(err) {
+		highest = max(highest, frame.Severity)
+	}
+
+	return highest
+}
+
+// Log logs err with the message and arguments provided, at the slog level of its Severity. The error is logged under
+// the "error" key, so handlers receive its LogValue.
+func Log(ctx context.Context, logger *slog.Logger, msg string, err error, args ...any) {
+	logger.Log(ctx, Severity(err).SlogLevel(), msg, append([]any{slog.Any("error", err)}, args...)...)
+}
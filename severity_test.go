@@ -0,0 +1,74 @@
+package falta_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"testing"
+
+	"github.com/a20r/falta"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSeverity(t *testing.T) {
+	as := assert.New(t)
+
+	errCacheMiss := falta.Newf("cache: miss for %s", falta.WithSeverity(falta.LevelDebug))
+	errCorrupt := falta.NewError("store: corrupt page", falta.WithSeverity(falta.LevelCritical))
+	errLoad := falta.NewError("api: cannot load user", falta.WithSeverity(falta.LevelWarn))
+
+	as.Equal(falta.LevelDebug, falta.Severity(errCacheMiss.New("user:42")))
+	as.Equal(falta.LevelCritical, falta.Severity(errLoad.Wrap(errCorrupt)), "the highest severity in the chain wins")
+	as.Equal(falta.LevelWarn, falta.Severity(fmt.Errorf("handler: %w", errLoad.Wrap(errCacheMiss.New("user:42")))))
+	as.Equal(falta.LevelCritical, falta.Severity(errors.Join(errCacheMiss.New("user:42"), errCorrupt)))
+	as.Equal(falta.Level(0), falta.Severity(falta.NewError("plain falta")))
+	as.Equal(falta.Level(0), falta.Severity(errors.New("plain")))
+	as.Equal(falta.Level(0), falta.Severity(nil))
+}
+
+func TestSeverity_Override(t *testing.T) {
+	as := assert.New(t)
+	errLoad := falta.NewError("api: cannot load user", falta.WithSeverity(falta.LevelError))
+
+	as.Equal(falta.LevelInfo, falta.Severity(errLoad.WithSeverity(falta.LevelInfo)), "the instance overrides its factory")
+	as.Equal(falta.LevelError, falta.Severity(errLoad), "WithSeverity must not mutate the error")
+	as.ErrorIs(errLoad.WithSeverity(falta.LevelInfo), errLoad)
+
+	base := falta.Newf("store: %s failed", falta.WithSeverity(falta.LevelWarn))
+	as.Equal(falta.LevelWarn, falta.Severity(base.Extend(falta.Newf("with %s")).New("db1", "timeout")))
+	as.Equal(falta.LevelCritical,
+		falta.Severity(base.Extend(falta.Newf("with %s", falta.WithSeverity(falta.LevelCritical))).New("db1", "corruption")))
+}
+
+func TestLevel_Strings(t *testing.T) {
+	as := assert.New(t)
+
+	as.Equal("critical", falta.LevelCritical.String())
+	as.Equal("unset", falta.Level(0).String())
+	as.Equal("Level(9)", falta.Level(9).String())
+
+	as.Equal(slog.LevelDebug, falta.LevelDebug.SlogLevel())
+	as.Equal(slog.LevelWarn, falta.LevelWarn.SlogLevel())
+	as.Equal(slog.LevelError+4, falta.LevelCritical.SlogLevel())
+	as.Equal(slog.LevelError, falta.Level(0).SlogLevel(), "an error without a severity is still an error")
+}
+
+func TestLog(t *testing.T) {
+	as := assert.New(t)
+	errCacheMiss := falta.Newf("cache: miss for %s", falta.WithSeverity(falta.LevelDebug))
+
+	buf := new(bytes.Buffer)
+	logger := slog.New(slog.NewJSONHandler(buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+
+	falta.Log(context.Background(), logger, "lookup failed", errCacheMiss.New("user:42"), "shard", 3)
+
+	var record map[string]any
+	as.NoError(json.Unmarshal(buf.Bytes(), &record))
+	as.Equal("DEBUG", record["level"])
+	as.Equal("lookup failed", record["msg"])
+	as.Equal(float64(3), record["shard"])
+	as.Equal(map[string]any{"msg": "cache: miss for user:42", "severity": "debug"}, record["error"])
+}
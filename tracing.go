@@ -0,0 +1,80 @@
+package falta
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+)
+
+// SpanRecorder records errors on the span active in a context. It is the seam between falta and a tracing library:
+// falta itself depends on none, and the otelfalta module adapts OpenTelemetry to it.
+type SpanRecorder interface {
+	// RecordError records err as an exception event, with the attributes provided, on the span active in ctx. It
+	// must do nothing if there is none.
+	RecordError(ctx context.Context, err error, attrs []Attr)
+}
+
+var spanRecorder atomic.Pointer[SpanRecorder]
+
+// SetSpanRecorder sets the recorder that NewCtx and the CaptureCtx function and method record their errors with.
+// Passing nil stops recording.
+//
+// Each error is recorded with the attributes "falta.declaration", "falta.code", "falta.fingerprint", "falta.id" and
+// "falta.payload", those that have a value, followed by the attributes of its chain. If its factory was declared with
+// WithStack, where it was built is recorded as "exception.stacktrace".
+func SetSpanRecorder(r SpanRecorder) {
+	if r == nil {
+		spanRecorder.Store(nil)
+		return
+	}
+
+	spanRecorder.Store(&r)
+}
+
+// recordSpan records f with the span recorder, if one is set, and returns f marked as recorded.
+func recordSpan(ctx context.Context, f Falta) Falta {
+	r := spanRecorder.Load()
+
+	if r == nil || ctx == nil {
+		return f
+	}
+
+	f.details = f.details.clone()
+	f.details.recorded = true
+	(*r).RecordError(ctx, f, spanAttrs(f))
+	return f
+}
+
+// recorded reports whether f was recorded with the span recorder when it was built.
+func (f Falta) recorded() bool {
+	return f.details != nil && f.details.recorded
+}
+
+func spanAttrs(f Falta) []Attr {
+	attrs := []Attr{{Key: "falta.declaration", Value: f.errFmt}}
+
+	if code := f.options().code; code != "" {
+		attrs = append(attrs, Attr{Key: "falta.code", Value: code})
+	}
+
+	attrs = append(attrs, Attr{Key: "falta.fingerprint", Value: Fingerprint(f)})
+
+	if id := ID(f); id != "" {
+		attrs = append(attrs, Attr{Key: "falta.id", Value: id})
+	}
+
+	if payload := f.payload(); payload != nil {
+		attrs = append(attrs, Attr{Key: "falta.payload", Value: fmt.Sprintf("%+v", payload)})
+	}
+
+	attrs = append(attrs, Attrs(f)...)
+
+	if caller := f.caller(); caller.Function != "" {
+		attrs = append(attrs, Attr{
+			Key:   "exception.stacktrace",
+			Value: fmt.Sprintf("%s\n\t%s:%d", caller.Function, caller.File, caller.Line),
+		})
+	}
+
+	return attrs
+}
@@ -0,0 +1,148 @@
+package falta_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/a20r/falta"
+	"github.com/stretchr/testify/assert"
+)
+
+type spanKey struct{}
+
+type recordedError struct {
+	span  string
+	err   error
+	attrs []falta.Attr
+}
+
+// memoryRecorder is a SpanRecorder that keeps what it records in memory. The active span is a name in the context.
+type memoryRecorder struct {
+	mu      sync.Mutex
+	records []recordedError
+}
+
+func (r *memoryRecorder) RecordError(ctx context.Context, err error, attrs []falta.Attr) {
+	span, ok := ctx.Value(spanKey{}).(string)
+
+	if !ok {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.records = append(r.records, recordedError{span: span, err: err, attrs: attrs})
+}
+
+func useRecorder(t *testing.T) *memoryRecorder {
+	r := new(memoryRecorder)
+	falta.SetSpanRecorder(r)
+	t.Cleanup(func() { falta.SetSpanRecorder(nil) })
+	return r
+}
+
+func attrMap(attrs []falta.Attr) map[string]any {
+	m := make(map[string]any, len(attrs))
+
+	for _, attr := range attrs {
+		m[attr.Key] = attr.Value
+	}
+
+	return m
+}
+
+func TestSpanRecorder_NewCtx(t *testing.T) {
+	as := assert.New(t)
+	r := useRecorder(t)
+
+	factory := falta.Newf("tracing test: no user with id %d", falta.WithCode("USER_NOT_FOUND"))
+	ctx := context.WithValue(context.Background(), spanKey{}, "GET /users/42")
+
+	err := falta.NewCtx(ctx, factory, 42)
+
+	as.Len(r.records, 1)
+	as.Equal("GET /users/42", r.records[0].span)
+	as.Equal(err, r.records[0].err)
+	as.Equal([]falta.Attr{
+		{Key: "falta.declaration", Value: "tracing test: no user with id %d"},
+		{Key: "falta.code", Value: "USER_NOT_FOUND"},
+		{Key: "falta.fingerprint", Value: falta.Fingerprint(err)},
+		{Key: "falta.payload", Value: "[42]"},
+	}, r.records[0].attrs)
+
+	_ = factory.New(42)
+	_ = falta.NewCtx(context.Background(), factory, 42)
+	as.Len(r.records, 1, "only NewCtx records, and only on an active span")
+}
+
+func TestSpanRecorder_CaptureCtx(t *testing.T) {
+	as := assert.New(t)
+	r := useRecorder(t)
+
+	errLoad := falta.NewM("tracing test: cannot load {{.user}}",
+		falta.WithStack(), falta.WithID(), falta.WithRedaction(falta.RedactFull, "user"))
+	ctx := context.WithValue(context.Background(), spanKey{}, "load")
+
+	err := func() (err error) {
+		defer errLoad.New(falta.M{"user": "ana"}).With("shard", 3).CaptureCtx(ctx, &err)
+		return errors.New("db: connection lost")
+	}()
+
+	as.Len(r.records, 1)
+	as.Equal(err, r.records[0].err)
+
+	attrs := attrMap(r.records[0].attrs)
+	as.Equal(falta.ID(err), attrs["falta.id"])
+	as.Equal("map[user:[REDACTED]]", attrs["falta.payload"], "the payload is recorded redacted")
+	as.Equal(3, attrs["shard"])
+	as.True(strings.HasPrefix(attrs["exception.stacktrace"].(string), "github.com/a20r/falta_test.TestSpanRecorder_CaptureCtx"))
+	as.NotContains(attrs, "falta.code")
+
+	_ = func() (err error) {
+		defer errLoad.New(falta.M{"user": "ana"}).CaptureCtx(ctx, &err)
+		return nil
+	}()
+	as.Len(r.records, 1, "nothing is recorded when there is no error")
+}
+
+func TestSpanRecorder_CaptureCtxFunc(t *testing.T) {
+	as := assert.New(t)
+	r := useRecorder(t)
+
+	errLoad := falta.Newf("tracing test: cannot load %s")
+	ctx := context.WithValue(context.Background(), spanKey{}, "load")
+
+	load := func(user string, cause error) (err error) {
+		defer falta.CaptureCtx(ctx, &err, errLoad, user)
+		return cause
+	}
+
+	for i := 0; i < 10; i++ {
+		as.NoError(load("ana", nil))
+	}
+
+	as.Empty(r.records, "nothing is recorded when there is no error")
+
+	err := load("ana", errors.New("db: connection lost"))
+	as.Len(r.records, 1)
+	as.Equal(err, r.records[0].err)
+	as.Equal("[ana]", attrMap(r.records[0].attrs)["falta.payload"])
+
+	_ = func() (err error) {
+		defer falta.NewCtx(ctx, errLoad, "bob").CaptureCtx(ctx, &err)
+		return errors.New("db: timeout")
+	}()
+	as.Len(r.records, 2, "an error recorded by NewCtx is not recorded again when it captures")
+}
+
+func TestSpanRecorder_Unset(t *testing.T) {
+	r := useRecorder(t)
+	falta.SetSpanRecorder(nil)
+
+	_ = falta.NewCtx(context.WithValue(context.Background(), spanKey{}, "x"), falta.Newf("tracing test: %d"), 1)
+	assert.Empty(t, r.records)
+}